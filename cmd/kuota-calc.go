@@ -6,14 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/postfinance/kuota-calc/internal/calc"
 	"github.com/spf13/cobra"
-	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const (
+	outputText          = "text"
+	outputResourceQuota = "resourcequota"
 )
 
 const (
@@ -21,7 +31,42 @@ const (
     cat deployment.yaml | kubectl %[1]s
 
     # do the same, calling the binary directly with detailed output
-    cat deployment.yaml | %[1]s --detailed`
+    cat deployment.yaml | %[1]s --detailed
+
+    # show the requests and limits of every container, the effective pod and the total per workload
+    cat deployment.yaml | %[1]s --tree
+
+    # count objects (pods, services, configmaps, count/deployments.apps, ...) as well
+    cat deployment.yaml | %[1]s --object-counts
+
+    # generate a ResourceQuota with 20%% headroom, rounded to friendly units
+    cat deployment.yaml | %[1]s --output resourcequota --namespace myapp --headroom 20 --round
+
+    # expect the jobs of cronjobs to run 30 minutes, overlapping jobs are taken into account
+    cat cronjob.yaml | %[1]s --job-runtime 30m
+
+    # simulate a week of cronjobs with individual runtimes and show when their resources peak
+    cat cronjobs.yaml | %[1]s --cronjob-peak --job-runtimes backup=2h,cleanup=5m
+
+    # show the totals per ResourceQuota scope (Terminating, NotBestEffort, PriorityClass, ...) as well
+    cat deployment.yaml | %[1]s --scopes
+
+    # check whether the deployment fits into an existing ResourceQuota
+    cat deployment.yaml | %[1]s --check-quota resourcequota.yaml
+
+    # take the pod overhead of RuntimeClasses into account
+    cat deployment.yaml | %[1]s --runtime-classes runtimeclasses.yaml
+
+    # run daemonsets on 40 nodes or on the matching nodes of a cluster (kubectl get nodes -o yaml)
+    cat daemonset.yaml | %[1]s --node-count 40
+    cat daemonset.yaml | %[1]s --nodes nodes.yaml
+
+    # apply the container defaults of the namespace LimitRange (can also be part of the piped manifests)
+    cat deployment.yaml | %[1]s --limit-ranges limitrange.yaml
+
+    # calculate autoscaled deployments with the maxReplicas of their HorizontalPodAutoscaler or KEDA ScaledObject
+    cat deployment.yaml hpa.yaml | %[1]s --detailed
+    cat deployment.yaml scaledobject.yaml | %[1]s --detailed`
 )
 
 // KuotaCalcOpts holds all command options.
@@ -29,9 +74,26 @@ type KuotaCalcOpts struct {
 	genericclioptions.IOStreams
 
 	// flags
-	debug    bool
-	detailed bool
-	version  bool
+	debug          bool
+	detailed       bool
+	tree           bool
+	version        bool
+	runtimeClasses []string
+	limitRanges    []string
+	nodeCount      int32
+	nodes          []string
+	emptyDirs      bool
+	objectCounts   bool
+	output         string
+	quotaName      string
+	namespace      string
+	headroom       int64
+	round          bool
+	checkQuotas    []string
+	scopes         bool
+	jobRuntime     time.Duration
+	jobRuntimes    map[string]string
+	cronJobPeak    bool
 	// files    []string
 
 	versionInfo *Version
@@ -61,6 +123,32 @@ func NewKuotaCalcCmd(version *Version, streams genericclioptions.IOStreams) *cob
 	cmd.Flags().BoolVar(&opts.debug, "debug", false, "enable debug logging")
 	cmd.Flags().BoolVar(&opts.detailed, "detailed", false, "enable detailed output")
 	cmd.Flags().BoolVar(&opts.version, "version", false, "print version and exit")
+	cmd.Flags().BoolVar(&opts.tree, "tree", false, "print the resources of every container, the effective pod and the total per resource")
+	cmd.Flags().BoolVar(&opts.emptyDirs, "empty-dirs", false, "account the sizeLimit of emptyDir volumes to the ephemeral-storage")
+	cmd.Flags().BoolVar(&opts.objectCounts, "object-counts", false,
+		"count objects by their quota count keys (e.g. pods, services or count/deployments.apps)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", outputText,
+		fmt.Sprintf("output format, one of: %s, %s", outputText, outputResourceQuota))
+	cmd.Flags().StringVar(&opts.quotaName, "quota-name", "kuota-calc", "name of the generated ResourceQuota")
+	cmd.Flags().StringVar(&opts.namespace, "namespace", "", "namespace of the generated ResourceQuota")
+	cmd.Flags().Int64Var(&opts.headroom, "headroom", 0, "headroom in percent, which is added to all resources of the generated ResourceQuota")
+	cmd.Flags().BoolVar(&opts.round, "round", false, "round the resources of the generated ResourceQuota up to friendly units")
+	cmd.Flags().DurationVar(&opts.jobRuntime, "job-runtime", 0,
+		"expected runtime of the jobs of cronjobs, limited by and defaults to their activeDeadlineSeconds")
+	cmd.Flags().StringToStringVar(&opts.jobRuntimes, "job-runtimes", nil,
+		"expected runtime of the jobs of single cronjobs by their name (e.g. backup=2h), overrides --job-runtime")
+	cmd.Flags().BoolVar(&opts.cronJobPeak, "cronjob-peak", false,
+		"simulate a week of all cronjob schedules and print the peak of their resources and when it happens")
+	cmd.Flags().BoolVar(&opts.scopes, "scopes", false, "print the totals per ResourceQuota scope, enables --object-counts")
+	cmd.Flags().StringSliceVar(&opts.checkQuotas, "check-quota", nil,
+		"file(s) containing ResourceQuota manifests, fails if the calculated resources exceed one of them")
+	cmd.Flags().StringSliceVar(&opts.runtimeClasses, "runtime-classes", nil,
+		"file(s) containing RuntimeClass manifests, used to resolve the pod overhead of pods with a runtimeClassName")
+	cmd.Flags().Int32Var(&opts.nodeCount, "node-count", 0, "number of nodes daemonsets run on")
+	cmd.Flags().StringSliceVar(&opts.nodes, "nodes", nil,
+		"file(s) containing Node manifests, daemonsets run on the nodes matching their node selector, affinity and tolerations")
+	cmd.Flags().StringSliceVar(&opts.limitRanges, "limit-ranges", nil,
+		"file(s) containing LimitRange manifests, used to default the resources of containers without requests or limits")
 
 	return cmd
 }
@@ -78,22 +166,41 @@ func (opts *KuotaCalcOpts) printVersion() error {
 
 func (opts *KuotaCalcOpts) run() error {
 	var (
-		summary []*calc.ResourceUsage
+		documents [][]byte
+		summary   []*calc.ResourceUsage
 	)
 
-	yamlReader := yaml.NewYAMLReader(bufio.NewReader(opts.In))
+	if opts.output != outputText && opts.output != outputResourceQuota {
+		return fmt.Errorf("unknown output format %q", opts.output)
+	}
 
-	for {
-		data, err := yamlReader.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
+	calculator, err := opts.newCalculator()
+	if err != nil {
+		return err
+	}
 
-			return fmt.Errorf("reading input: %w", err)
+	err = readDocuments(opts.In, func(data []byte) error {
+		documents = append(documents, data)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// LimitRanges and autoscalers apply to all documents, regardless of their position in the input
+	for _, data := range documents {
+		if err := calculator.AddLimitRange(data); err != nil && !errors.Is(err, calc.ErrResourceNotSupported) {
+			return err
+		}
+
+		if err := calculator.AddAutoscaler(data); err != nil && !errors.Is(err, calc.ErrResourceNotSupported) {
+			return err
 		}
+	}
 
-		usage, err := calc.ResourceQuotaFromYaml(data)
+	for _, data := range documents {
+		usage, err := calculator.ResourceQuotaFromYaml(data)
 		if err != nil {
 			if errors.Is(err, calc.ErrResourceNotSupported) {
 				if opts.debug {
@@ -109,31 +216,142 @@ func (opts *KuotaCalcOpts) run() error {
 		summary = append(summary, usage)
 	}
 
-	if opts.detailed {
+	switch {
+	case len(opts.checkQuotas) > 0:
+		return opts.checkResourceQuotas(summary)
+	case opts.output == outputResourceQuota:
+		return opts.printResourceQuota(summary)
+	case opts.tree:
+		opts.printTree(summary)
+	case opts.detailed:
 		opts.printDetailed(summary)
-	} else {
+	default:
 		opts.printSummary(summary)
 	}
 
+	if opts.cronJobPeak {
+		opts.printCronJobPeaks(summary)
+	}
+
+	if opts.scopes {
+		opts.printScopes(summary)
+	}
+
+	return nil
+}
+
+// newCalculator returns a calculator configured by the command options, with all RuntimeClasses and
+// LimitRanges of the given files registered.
+func (opts *KuotaCalcOpts) newCalculator() (*calc.Calculator, error) {
+	calculator := calc.NewCalculator()
+	calculator.EmptyDirs = opts.emptyDirs
+	calculator.JobRuntime = opts.jobRuntime
+	calculator.NodeCount = opts.nodeCount
+	calculator.JobRuntimes = make(map[string]time.Duration, len(opts.jobRuntimes))
+
+	for name, value := range opts.jobRuntimes {
+		runtime, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("job runtime of %s: %w", name, err)
+		}
+
+		calculator.JobRuntimes[name] = runtime
+	}
+	calculator.ObjectCounts = opts.objectCounts || opts.scopes || len(opts.checkQuotas) > 0
+
+	for _, file := range opts.runtimeClasses {
+		if err := readFile(file, calculator.AddRuntimeClass); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range opts.nodes {
+		if err := readFile(file, calculator.AddNode); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range opts.limitRanges {
+		if err := readFile(file, calculator.AddLimitRange); err != nil {
+			return nil, err
+		}
+	}
+
+	return calculator, nil
+}
+
+// readDocuments reads all yaml documents from r and calls fn for each of them.
+func readDocuments(r io.Reader, fn func(data []byte) error) error {
+	yamlReader := yaml.NewYAMLReader(bufio.NewReader(r))
+
+	for {
+		data, err := yamlReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("reading input: %w", err)
+		}
+
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+}
+
+// readFile reads all yaml documents from a file and calls fn for each of them.
+func readFile(name string, fn func(data []byte) error) error {
+	f, err := os.Open(name) //nolint:gosec // reading user supplied files is intended
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if err := readDocuments(f, fn); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
 	return nil
 }
 
 func (opts *KuotaCalcOpts) printDetailed(usage []*calc.ResourceUsage) {
+	total := totalResources(usage)
+	names := resourceNames(total)
+
 	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
 
-	fmt.Fprintf(w, "Version\tKind\tName\tReplicas\tStrategy\tMaxReplicas\tCPU\tMemory\t\n")
+	fmt.Fprintf(w, "Version\tKind\tName\tReplicas\tAutoscalerMax\tStrategy\tMaxReplicas\t")
+
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t", name)
+	}
+
+	fmt.Fprintf(w, "\n")
 
 	for _, u := range usage {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\t\n",
+		autoscalerMax := "-"
+		if u.Details.AutoscalerMaxReplicas > 0 {
+			autoscalerMax = fmt.Sprintf("%d", u.Details.AutoscalerMaxReplicas)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%d\t",
 			u.Details.Version,
 			u.Details.Kind,
 			u.Details.Name,
 			u.Details.Replicas,
+			autoscalerMax,
 			u.Details.Strategy,
 			u.Details.MaxReplicas,
-			u.CPU,
-			u.Memory,
 		)
+
+		for _, name := range names {
+			quantity := u.Resources[name]
+			fmt.Fprintf(w, "%s\t", quantity.String())
+		}
+
+		fmt.Fprintf(w, "\n")
 	}
 
 	w.Flush()
@@ -143,19 +361,255 @@ func (opts *KuotaCalcOpts) printDetailed(usage []*calc.ResourceUsage) {
 	opts.printSummary(usage)
 }
 
+// printTree prints every k8s resource with its containers, the effective resources of a single pod
+// and the total resources, which are multiplied by the number of replicas.
+func (opts *KuotaCalcOpts) printTree(usage []*calc.ResourceUsage) {
+	for _, u := range usage {
+		fmt.Fprintf(opts.Out, "%s %s %s", u.Details.Version, u.Details.Kind, u.Details.Name)
+
+		if u.PodResources != nil {
+			fmt.Fprintf(opts.Out, " (replicas: %d", u.Details.Replicas)
+
+			if u.Details.AutoscalerMaxReplicas > 0 {
+				fmt.Fprintf(opts.Out, ", autoscaler max replicas: %d", u.Details.AutoscalerMaxReplicas)
+			}
+
+			fmt.Fprintf(opts.Out, ", max replicas: %d", u.Details.MaxReplicas)
+
+			if u.Details.Strategy != "" {
+				fmt.Fprintf(opts.Out, ", strategy: %s", u.Details.Strategy)
+			}
+
+			fmt.Fprintf(opts.Out, ")")
+		}
+
+		fmt.Fprintf(opts.Out, "\n")
+
+		for i := range u.Containers {
+			container := u.Containers[i]
+			kind := "container"
+
+			switch {
+			case container.Sidecar:
+				kind = "sidecar"
+			case container.Init:
+				kind = "init container"
+			}
+
+			fmt.Fprintf(opts.Out, "├── %s %s: %s\n", kind, container.Name, formatResources(container.Resources))
+		}
+
+		if u.PodResources != nil {
+			fmt.Fprintf(opts.Out, "├── pod: %s\n", formatResources(u.PodResources))
+		}
+
+		fmt.Fprintf(opts.Out, "└── total: %s\n\n", formatResources(u.Resources))
+	}
+
+	fmt.Fprintf(opts.Out, "Total\n")
+
+	opts.printSummary(usage)
+}
+
+// formatResources formats a resource list as name=quantity pairs.
+func formatResources(list v1.ResourceList) string {
+	names := resourceNames(list)
+	pairs := make([]string, 0, len(names))
+
+	for _, name := range names {
+		quantity := list[name]
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
 func (opts *KuotaCalcOpts) printSummary(usage []*calc.ResourceUsage) {
-	var (
-		cpuUsage    resource.Quantity
-		memoryUsage resource.Quantity
-	)
+	total := totalResources(usage)
+
+	for _, name := range resourceNames(total) {
+		quantity := total[name]
+		fmt.Fprintf(opts.Out, "%s: %s\n", name, quantity.String())
+	}
+}
+
+// printCronJobPeaks prints the peak of every resource used by the jobs of all cronjobs during a week
+// and when it is first reached.
+func (opts *KuotaCalcOpts) printCronJobPeaks(usage []*calc.ResourceUsage) {
+	peaks := calc.CronJobPeaks(usage)
+	list := make(v1.ResourceList, len(peaks))
+	times := make(map[v1.ResourceName]time.Time, len(peaks))
+
+	for _, peak := range peaks {
+		list[peak.Name] = peak.Quantity
+		times[peak.Name] = peak.Time
+	}
+
+	fmt.Fprintf(opts.Out, "\nCronJob peak (simulated week)\n")
+
+	for _, name := range resourceNames(list) {
+		quantity := list[name]
+		fmt.Fprintf(opts.Out, "%s: %s at %s\n", name, quantity.String(), times[name].Format("Mon 15:04 MST"))
+	}
+}
+
+func (opts *KuotaCalcOpts) printScopes(usage []*calc.ResourceUsage) {
+	for _, scope := range calc.ScopeTotals(usage) {
+		fmt.Fprintf(opts.Out, "\nScope %s\n", scope)
+
+		for _, name := range resourceNames(scope.Resources) {
+			quantity := scope.Resources[name]
+			fmt.Fprintf(opts.Out, "%s: %s\n", name, quantity.String())
+		}
+	}
+}
+
+func (opts *KuotaCalcOpts) printResourceQuota(usage []*calc.ResourceUsage) error {
+	quota := calc.ResourceQuota(totalResources(usage), calc.QuotaOptions{
+		Name:      opts.quotaName,
+		Namespace: opts.namespace,
+		Headroom:  opts.headroom,
+		Round:     opts.round,
+	})
+
+	printer := printers.YAMLPrinter{}
+
+	return printer.PrintObj(quota, opts.Out)
+}
+
+func (opts *KuotaCalcOpts) checkResourceQuotas(usage []*calc.ResourceUsage) error {
+	var exceeded []string
+
+	for _, file := range opts.checkQuotas {
+		err := readFile(file, func(data []byte) error {
+			quota, err := calc.DecodeResourceQuota(data)
+			if err != nil {
+				return err
+			}
+
+			if opts.printQuotaStatus(quota, calc.CheckResourceQuota(quota, usage)) {
+				exceeded = append(exceeded, quota.Name)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%w: %s", calc.ErrQuotaExceeded, strings.Join(exceeded, ", "))
+	}
+
+	return nil
+}
+
+// printQuotaStatus prints the used, hard and remaining resources of a ResourceQuota and returns true,
+// if any resource exceeds its hard limit.
+func (opts *KuotaCalcOpts) printQuotaStatus(quota *v1.ResourceQuota, status []calc.QuotaStatus) bool {
+	exceeded := false
+
+	fmt.Fprintf(opts.Out, "ResourceQuota %s\n", quota.Name)
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	fmt.Fprintf(w, "Resource\tUsed\tHard\tRemaining\tStatus\t\n")
+
+	for i := range status {
+		s := status[i]
+		state := "OK"
+
+		if s.Exceeded() {
+			state = "EXCEEDED"
+			exceeded = true
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", s.Name, s.Used.String(), s.Hard.String(), s.Remaining.String(), state)
+	}
+
+	w.Flush()
+
+	fmt.Fprintf(opts.Out, "\n")
+
+	return exceeded
+}
+
+// totalResources sums up the resources of all resource usages.
+func totalResources(usage []*calc.ResourceUsage) v1.ResourceList {
+	total := v1.ResourceList{}
 
 	for _, u := range usage {
-		cpuUsage.Add(*u.CPU)
-		memoryUsage.Add(*u.Memory)
+		for name, quantity := range u.Resources {
+			value := total[name]
+			value.Add(quantity)
+			total[name] = value
+		}
 	}
 
-	fmt.Fprintf(opts.Out, "CPU: %s\nMemory: %s\n",
-		cpuUsage.String(),
-		memoryUsage.String(),
-	)
+	return total
+}
+
+// resourceNames returns the names of all resources in list. Compute resources (cpu, memory and
+// ephemeral-storage first) come before storage resources, followed by all other resources. Requests
+// are placed before limits.
+func resourceNames(list v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(list))
+
+	for name := range list {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		iRank, iName, iPrefix := resourceOrder(names[i])
+		jRank, jName, jPrefix := resourceOrder(names[j])
+
+		if iRank != jRank {
+			return iRank < jRank
+		}
+
+		if iName != jName {
+			return iName < jName
+		}
+
+		return iPrefix < jPrefix
+	})
+
+	return names
+}
+
+// resourceOrder splits a quota resource name into its sort keys.
+func resourceOrder(name v1.ResourceName) (rank int, resourceName string, prefix int) {
+	resourceName = string(name)
+
+	switch {
+	case strings.HasPrefix(resourceName, v1.DefaultResourceRequestsPrefix):
+		resourceName = strings.TrimPrefix(resourceName, v1.DefaultResourceRequestsPrefix)
+	case strings.HasPrefix(resourceName, "limits."):
+		resourceName = strings.TrimPrefix(resourceName, "limits.")
+		prefix = 1
+	default:
+		prefix = 2
+	}
+
+	switch {
+	case resourceName == string(v1.ResourceCPU):
+		rank = 0
+	case resourceName == string(v1.ResourceMemory):
+		rank = 1
+	case resourceName == string(v1.ResourceEphemeralStorage):
+		rank = 2
+	case resourceName == string(v1.ResourceStorage):
+		rank = 4
+	case resourceName == string(v1.ResourcePersistentVolumeClaims):
+		rank = 5
+	case strings.Contains(resourceName, ".storageclass.storage.k8s.io/"):
+		rank = 6
+	case prefix < 2:
+		rank = 3
+	default:
+		rank = 7
+	}
+
+	return rank, resourceName, prefix
 }
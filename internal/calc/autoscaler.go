@@ -0,0 +1,87 @@
+package calc
+
+import (
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// AddAutoscaler decodes a single yaml document containing an autoscaler and registers the maximum
+// replicas of its scale target. Deployments, StatefulSets, ReplicaSets and ReplicationControllers
+// matching the kind and name of a scale target are calculated with the maximum replicas of the
+// autoscaler instead of their own replicas. ErrResourceNotSupported is returned for all other k8s
+// resources.
+// Currently supported:
+// * autoscaling/v2 - HorizontalPodAutoscaler
+// * autoscaling/v1 - HorizontalPodAutoscaler
+// * keda.sh/v1alpha1 - ScaledObject
+func (c *Calculator) AddAutoscaler(yamlData []byte) error {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil {
+		if !runtime.IsNotRegisteredError(err) {
+			return fmt.Errorf("decoding yaml data: %w", err)
+		}
+
+		if object, gvk, err = decodeKEDA(yamlData); err != nil {
+			return err
+		}
+	}
+
+	switch obj := object.(type) {
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		c.autoscalers[targetKey(obj.Spec.ScaleTargetRef.Kind, obj.Spec.ScaleTargetRef.Name)] = autoscaler{
+			maxReplicas: obj.Spec.MaxReplicas,
+		}
+	case *autoscalingv1.HorizontalPodAutoscaler:
+		c.autoscalers[targetKey(obj.Spec.ScaleTargetRef.Kind, obj.Spec.ScaleTargetRef.Name)] = autoscaler{
+			maxReplicas: obj.Spec.MaxReplicas,
+		}
+	case *scaledObject:
+		// KEDA activates its scale target, when there are events to process
+		c.autoscalers[targetKey(obj.scaleTargetKind(), obj.Spec.ScaleTargetRef.Name)] = autoscaler{
+			maxReplicas: maxReplicaCount(obj.Spec.MaxReplicaCount),
+			fromZero:    true,
+		}
+	default:
+		calcErr := CalculationError{
+			err: ErrResourceNotSupported,
+		}
+
+		if gvk != nil {
+			calcErr.Version = gvk.Version
+			calcErr.Kind = gvk.Kind
+		}
+
+		return calcErr
+	}
+
+	return nil
+}
+
+// autoscaler contains the maximum replicas an autoscaler scales its target to.
+type autoscaler struct {
+	maxReplicas int32
+	// fromZero is true, if the autoscaler scales targets with zero replicas. The
+	// HorizontalPodAutoscaler is disabled for them.
+	fromZero bool
+}
+
+// scaledReplicas returns the number of replicas a k8s resource runs at most and the maximum replicas
+// of its autoscaler, which is 0 if the k8s resource is not autoscaled.
+func (c *Calculator) scaledReplicas(kind, name string, replicas int32) (scaled, autoscalerMax int32) {
+	scaler, ok := c.autoscalers[targetKey(kind, name)]
+	if !ok || (replicas == 0 && !scaler.fromZero) {
+		return replicas, 0
+	}
+
+	return scaler.maxReplicas, scaler.maxReplicas
+}
+
+// targetKey returns the key of a scale target. The api group is not part of the key, as deprecated
+// group versions are converted to their current types.
+func targetKey(kind, name string) string {
+	return kind + "/" + name
+}
@@ -0,0 +1,119 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAutoscaler(t *testing.T) {
+	var tests = []struct {
+		name          string
+		autoscalers   []string
+		resource      string
+		cpu           resource.Quantity
+		replicas      int32
+		maxReplicas   int32
+		autoscalerMax int32
+	}{
+		{
+			name:        "deployment without autoscaler",
+			resource:    normalDeployment,
+			cpu:         resource.MustParse("6500m"),
+			replicas:    10,
+			maxReplicas: 13,
+		},
+		{
+			name:          "deployment",
+			autoscalers:   []string{normalHorizontalPodAutoscaler},
+			resource:      normalDeployment,
+			cpu:           resource.MustParse("19"),
+			replicas:      10,
+			maxReplicas:   38,
+			autoscalerMax: 30,
+		},
+		{
+			name:        "deployment with zero replicas",
+			autoscalers: []string{zeroReplicaHorizontalPodAutoscaler},
+			resource:    zeroReplicaDeployment,
+			cpu:         resource.MustParse("0"),
+			replicas:    0,
+			maxReplicas: 0,
+		},
+		{
+			name:          "statefulset",
+			autoscalers:   []string{normalHorizontalPodAutoscaler, statefulSetHorizontalPodAutoscaler},
+			resource:      normalStatefulSet,
+			cpu:           resource.MustParse("5"),
+			replicas:      2,
+			maxReplicas:   5,
+			autoscalerMax: 5,
+		},
+		{
+			name:          "deployment with zero replicas and keda",
+			autoscalers:   []string{zeroReplicaScaledObject},
+			resource:      zeroReplicaDeployment,
+			cpu:           resource.MustParse("10"),
+			replicas:      0,
+			maxReplicas:   10,
+			autoscalerMax: 8,
+		},
+		{
+			name:          "statefulset with keda default max replicas",
+			autoscalers:   []string{statefulSetScaledObject},
+			resource:      normalStatefulSet,
+			cpu:           resource.MustParse("100"),
+			replicas:      2,
+			maxReplicas:   100,
+			autoscalerMax: 100,
+		},
+		{
+			name:        "replicaset with the name of another scale target",
+			autoscalers: []string{statefulSetHorizontalPodAutoscaler},
+			resource:    normalReplicaSet,
+			cpu:         resource.MustParse("3"),
+			replicas:    3,
+			maxReplicas: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+
+			for _, autoscaler := range test.autoscalers {
+				r.NoError(calculator.AddAutoscaler([]byte(autoscaler)))
+			}
+
+			usage, err := calculator.ResourceQuotaFromYaml([]byte(test.resource))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+			r.Equalf(test.autoscalerMax, usage.Details.AutoscalerMaxReplicas, "autoscalerMaxReplicas")
+		})
+	}
+}
+
+func TestAddAutoscaler(t *testing.T) {
+	r := require.New(t)
+
+	err := NewCalculator().AddAutoscaler([]byte(normalDeployment))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+
+	err = NewCalculator().AddAutoscaler([]byte(normalScaledJob))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+
+	err = NewCalculator().AddAutoscaler([]byte(unsupportedOpenshiftRoute))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+}
@@ -4,16 +4,27 @@ package calc
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	"gopkg.in/inf.v0"
 	appsv1 "k8s.io/api/apps/v1"
 	batchV1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
+const (
+	milliScale   = 3
+	limitsPrefix = "limits."
+)
+
 var (
 	// ErrResourceNotSupported is returned if a k8s resource is not supported by kuota-calc.
 	ErrResourceNotSupported = errors.New("resource not supported")
@@ -42,9 +53,90 @@ func (cErr CalculationError) Unwrap() error {
 
 // ResourceUsage summarizes the usage of compute resources for a k8s resource.
 type ResourceUsage struct {
-	CPU     *resource.Quantity
-	Memory  *resource.Quantity
-	Details Details
+	// Resources contains the resource usage keyed by the resource names used in a ResourceQuota
+	// (e.g. requests.cpu, limits.memory or requests.nvidia.com/gpu).
+	Resources v1.ResourceList
+	Details   Details
+	// PodResources contains the effective resources of a single pod, before they are multiplied by
+	// the number of replicas. It is nil for k8s resources, which do not create pods.
+	PodResources v1.ResourceList
+	// Containers contains the resources of all init containers and containers of a single pod.
+	Containers []ContainerResources
+	// CronJob contains the schedule of a cronjob, it is nil for all other k8s resources.
+	CronJob *CronJobSchedule
+}
+
+// ContainerResources contains the requests and limits of a single container, keyed by the resource
+// names used in a ResourceQuota.
+type ContainerResources struct {
+	Name      string
+	Init      bool
+	Sidecar   bool
+	Resources v1.ResourceList
+}
+
+// quotaResources converts the requests and limits of a pod into a resource list keyed by the resource
+// names used in a ResourceQuota. A ResourceQuota only supports limits for cpu, memory and
+// ephemeral-storage, all other limits (e.g. of extended resources or hugepages) must be equal to the
+// requests and are not part of the result.
+func quotaResources(requests, limits v1.ResourceList) v1.ResourceList {
+	resources := make(v1.ResourceList, len(requests)+len(limits))
+
+	for name, quantity := range requests {
+		resources[v1.ResourceName(v1.DefaultResourceRequestsPrefix+name)] = quantity
+	}
+
+	for name, quantity := range limits {
+		switch name {
+		case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+			resources[v1.ResourceName(limitsPrefix+name)] = quantity
+		}
+	}
+
+	return resources
+}
+
+// mulResourceList multiplies all quantities of list by factor.
+func mulResourceList(list v1.ResourceList, factor int64) {
+	for name, quantity := range list {
+		list[name] = mulQuantity(quantity, factor, 1)
+	}
+}
+
+// mulQuantity multiplies a quantity by numerator/denominator. The calculation is exact, only the
+// result is rounded up to milli units, the precision of all quantities calculated by kuota-calc.
+func mulQuantity(quantity resource.Quantity, numerator, denominator int64) resource.Quantity {
+	product := new(inf.Dec).Mul(quantity.AsDec(), inf.NewDec(numerator, 0))
+	result := new(inf.Dec).QuoRound(product, inf.NewDec(denominator, 0), milliScale, inf.RoundCeil)
+
+	return *resource.NewDecimalQuantity(*result, quantity.Format)
+}
+
+// addResourceList adds all quantities of src to dst.
+func addResourceList(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		value := dst[name]
+		value.Add(quantity)
+		dst[name] = value
+	}
+}
+
+// subResourceList subtracts all quantities of src from dst.
+func subResourceList(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		value := dst[name]
+		value.Sub(quantity)
+		dst[name] = value
+	}
+}
+
+// maxResourceList sets every quantity of dst to the value of src, if that value is larger.
+func maxResourceList(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		if value, ok := dst[name]; !ok || quantity.Cmp(value) > 0 {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
 }
 
 // Details contains a few details of a k8s resource, which are needed to generate a detailed resource
@@ -56,27 +148,197 @@ type Details struct {
 	Strategy    string
 	Replicas    int32
 	MaxReplicas int32
+	// AutoscalerMaxReplicas contains the maximum replicas of the autoscaler scaling the k8s resource,
+	// it is 0 if the k8s resource is not autoscaled.
+	AutoscalerMaxReplicas int32
+	// Pod contains the properties of the created pods, it is nil for k8s resources which do not
+	// create any pods.
+	Pod *PodDetails
+}
+
+// podResources calculates the effective requests and limits of a pod, keyed by the resource names
+// used in a ResourceQuota. Missing requests default to the limits. The pod overhead is added to the
+// requests and to all limits which are set. If enabled, the size limits of emptyDir volumes are added
+// to the ephemeral-storage requests and limits.
+func (c *Calculator) podResources(podSpec *v1.PodSpec) v1.ResourceList {
+	requestList := effectiveResources(podSpec, defaultRequests)
+	limitList := effectiveResources(podSpec, func(r v1.ResourceRequirements) v1.ResourceList {
+		return r.Limits
+	})
+
+	addResourceList(requestList, podSpec.Overhead)
+
+	for name, quantity := range podSpec.Overhead {
+		if value, ok := limitList[name]; ok && !value.IsZero() {
+			value.Add(quantity)
+			limitList[name] = value
+		}
+	}
+
+	if c.EmptyDirs {
+		emptyDirs := emptyDirResources(podSpec)
+
+		addResourceList(requestList, emptyDirs)
+		addResourceList(limitList, emptyDirs)
+	}
+
+	return quotaResources(requestList, limitList)
 }
 
-func podResources(podSpec *v1.PodSpec) (cpu, memory *resource.Quantity) {
-	cpu = new(resource.Quantity)
-	memory = new(resource.Quantity)
+// containerResources returns the requests and limits of all init containers and containers of a pod.
+func containerResources(podSpec *v1.PodSpec) []ContainerResources {
+	containers := make([]ContainerResources, 0, len(podSpec.InitContainers)+len(podSpec.Containers))
+
+	for i := range podSpec.InitContainers {
+		container := podSpec.InitContainers[i]
+
+		containers = append(containers, ContainerResources{
+			Name:      container.Name,
+			Init:      true,
+			Sidecar:   isSidecar(&container),
+			Resources: quotaResources(defaultRequests(container.Resources), container.Resources.Limits),
+		})
+	}
 
 	for i := range podSpec.Containers {
 		container := podSpec.Containers[i]
 
-		cpu.Add(*container.Resources.Limits.Cpu())
-		memory.Add(*container.Resources.Limits.Memory())
+		containers = append(containers, ContainerResources{
+			Name:      container.Name,
+			Resources: quotaResources(defaultRequests(container.Resources), container.Resources.Limits),
+		})
+	}
+
+	return containers
+}
+
+// defaultRequests returns the requests of a container. Requests which are not set default to the limits of
+// the same resource, like the API server does when a pod is created.
+func defaultRequests(resources v1.ResourceRequirements) v1.ResourceList {
+	if len(resources.Limits) == 0 {
+		return resources.Requests
+	}
+
+	list := resources.Requests.DeepCopy()
+	if list == nil {
+		list = v1.ResourceList{}
+	}
+
+	for name, quantity := range resources.Limits {
+		if _, ok := list[name]; !ok {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+
+	return list
+}
+
+// effectiveResources calculates the effective resources of a pod the same way the scheduler and the
+// quota admission do. Init containers run one after another before the app containers are started,
+// so the effective value of each resource is the larger one of the sum of all app containers and the
+// biggest init container. Sidecars (init containers with restartPolicy Always) keep running once they
+// are started, so they add to the app containers and to every init container started after them.
+func effectiveResources(podSpec *v1.PodSpec, resources func(v1.ResourceRequirements) v1.ResourceList) v1.ResourceList {
+	var (
+		effective      = v1.ResourceList{}
+		initContainers = v1.ResourceList{} // biggest usage while running init containers
+		sidecars       = v1.ResourceList{} // sum of all sidecars started so far
+	)
+
+	for i := range podSpec.Containers {
+		addResourceList(effective, resources(podSpec.Containers[i].Resources))
 	}
 
 	for i := range podSpec.InitContainers {
 		container := podSpec.InitContainers[i]
+		containerResources := resources(container.Resources)
+
+		if isSidecar(&container) {
+			addResourceList(effective, containerResources)
+			addResourceList(sidecars, containerResources)
+			maxResourceList(initContainers, sidecars)
+
+			continue
+		}
 
-		cpu.Add(*container.Resources.Limits.Cpu())
-		memory.Add(*container.Resources.Limits.Memory())
+		current := sidecars.DeepCopy()
+		addResourceList(current, containerResources)
+		maxResourceList(initContainers, current)
 	}
 
-	return
+	maxResourceList(effective, initContainers)
+
+	return effective
+}
+
+// emptyDirResources returns the sum of the size limits of all disk backed emptyDir volumes as
+// ephemeral-storage. Memory backed emptyDir volumes are accounted to the memory of the containers.
+func emptyDirResources(podSpec *v1.PodSpec) v1.ResourceList {
+	var storage resource.Quantity
+
+	for i := range podSpec.Volumes {
+		emptyDir := podSpec.Volumes[i].EmptyDir
+
+		if emptyDir == nil || emptyDir.Medium == v1.StorageMediumMemory || emptyDir.SizeLimit == nil {
+			continue
+		}
+
+		storage.Add(*emptyDir.SizeLimit)
+	}
+
+	if storage.IsZero() {
+		return nil
+	}
+
+	return v1.ResourceList{
+		v1.ResourceEphemeralStorage: storage,
+	}
+}
+
+// isSidecar returns true if the init container is a sidecar container, which keeps running during
+// the whole lifetime of the pod.
+func isSidecar(container *v1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways
+}
+
+// Calculator calculates the resource usage of k8s resources. Additional k8s resources, which affect the
+// resource usage of pods (e.g. RuntimeClasses), can be registered on the calculator.
+type Calculator struct {
+	// EmptyDirs enables accounting the size limits of emptyDir volumes to the ephemeral-storage.
+	EmptyDirs bool
+	// ObjectCounts enables counting objects by their ResourceQuota count keys (e.g. pods, services or
+	// count/deployments.apps). With object counts enabled, unsupported k8s resources are counted
+	// instead of returning ErrResourceNotSupported.
+	ObjectCounts bool
+	// JobRuntime is the expected runtime of the jobs of cronjobs. It is used to calculate how many jobs
+	// overlap, if the concurrency policy allows it. If not set, the activeDeadlineSeconds of the jobs is
+	// used instead.
+	JobRuntime time.Duration
+	// JobRuntimes contains the expected runtime of the jobs of single cronjobs by their name, it takes
+	// precedence over JobRuntime.
+	JobRuntimes map[string]time.Duration
+	// NodeCount is the number of nodes daemonsets run on, if no nodes are registered with AddNode.
+	NodeCount int32
+
+	runtimeClasses map[string]v1.ResourceList
+	limitRanges    []v1.ResourceRequirements
+	nodes          []v1.Node
+	autoscalers    map[string]autoscaler
+}
+
+// NewCalculator returns a new Calculator without any registered k8s resources.
+func NewCalculator() *Calculator {
+	return &Calculator{
+		runtimeClasses: make(map[string]v1.ResourceList),
+		autoscalers:    make(map[string]autoscaler),
+	}
+}
+
+// ResourceQuotaFromYaml decodes a single yaml document into a k8s object. Then performs a type assertion
+// on the object and calculates the resource needs of it. No additional k8s resources are taken into
+// account, see Calculator for that.
+func ResourceQuotaFromYaml(yamlData []byte) (*ResourceUsage, error) {
+	return NewCalculator().ResourceQuotaFromYaml(yamlData)
 }
 
 // ResourceQuotaFromYaml decodes a single yaml document into a k8s object. Then performs a type assertion
@@ -85,39 +347,24 @@ func podResources(podSpec *v1.PodSpec) (cpu, memory *resource.Quantity) {
 // * apps/v1 - Deployment
 // * apps/v1 - StatefulSet
 // * apps/v1 - DaemonSet
+// * apps/v1 - ReplicaSet
 // * batch/v1 - CronJob
 // * batch/v1 - Job
 // * v1 - Pod
-func ResourceQuotaFromYaml(yamlData []byte) (*ResourceUsage, error) {
-	var version string
-
-	var kind string
-
-	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
-
+// * v1 - PersistentVolumeClaim
+// * v1 - ReplicationController
+// * keda.sh/v1alpha1 - ScaledJob
+// Deployments, StatefulSets, DaemonSets, ReplicaSets and CronJobs of deprecated group versions (e.g.
+// extensions/v1beta1 or batch/v1beta1) are converted to their current types.
+func (c *Calculator) ResourceQuotaFromYaml(yamlData []byte) (*ResourceUsage, error) {
+	object, gvk, err := decode(yamlData)
 	if err != nil {
-		// when the kind is not found, I just warn and skip
-		if runtime.IsNotRegisteredError(err) {
-			log.Warn().Msg(err.Error())
-
-			unknown := runtime.Unknown{Raw: yamlData}
-
-			if _, gvk1, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, &unknown); err == nil {
-				kind = gvk1.Kind
-				version = gvk1.Version
-			}
-		} else {
-			return nil, fmt.Errorf("decoding yaml data: %w", err)
-		}
-	} else {
-		kind = gvk.Kind
-		version = gvk.Version
+		return nil, err
 	}
 
-	switch obj := object.(type) {
-	case *appsv1.Deployment:
-		usage, err := deployment(*obj)
-		if err != nil {
+	usage, err := c.resourceUsage(object)
+	if err != nil {
+		if !c.ObjectCounts || !errors.Is(err, ErrResourceNotSupported) {
 			return nil, CalculationError{
 				Version: gvk.Version,
 				Kind:    gvk.Kind,
@@ -125,22 +372,151 @@ func ResourceQuotaFromYaml(yamlData []byte) (*ResourceUsage, error) {
 			}
 		}
 
-		return usage, nil
+		// unsupported resources have no resource usage, but are still counted
+		usage = &ResourceUsage{
+			Resources: v1.ResourceList{},
+			Details: Details{
+				Version: gvk.GroupVersion().String(),
+				Kind:    gvk.Kind,
+				Name:    objectName(object),
+			},
+		}
+	}
+
+	if c.ObjectCounts {
+		addResourceList(usage.Resources, objectCounts(object, gvk, usage))
+	}
+
+	return usage, nil
+}
+
+// decode decodes a single yaml document into a k8s object. Kinds which are not registered in the
+// client-go scheme are decoded into an unstructured object, deprecated group versions are converted to
+// their current types.
+func decode(yamlData []byte) (runtime.Object, *schema.GroupVersionKind, error) {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err == nil {
+		return convertLegacy(object, gvk)
+	}
+
+	if !runtime.IsNotRegisteredError(err) {
+		return nil, nil, fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	// KEDA resources are not registered in the client-go scheme
+	kedaObject, kedaGVK, kedaErr := decodeKEDA(yamlData)
+	if kedaErr != nil || kedaObject != nil {
+		return kedaObject, kedaGVK, kedaErr
+	}
+
+	// when the kind is not found, I just warn and skip
+	log.Warn().Msg(err.Error())
+
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(yamlData, &obj.Object); err != nil {
+		return nil, nil, fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	unknownGVK := obj.GroupVersionKind()
+
+	return obj, &unknownGVK, nil
+}
+
+// resourceUsage performs a type assertion on the object and calculates the resource needs of it.
+func (c *Calculator) resourceUsage(object runtime.Object) (*ResourceUsage, error) {
+	spec := podSpec(object)
+	if spec != nil {
+		c.admit(spec)
+	}
+
+	usage, err := c.calculate(object)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec != nil {
+		usage.Details.Pod = podDetails(spec)
+		usage.PodResources = c.podResources(spec)
+		usage.Containers = containerResources(spec)
+	}
+
+	return usage, nil
+}
+
+func (c *Calculator) calculate(object runtime.Object) (*ResourceUsage, error) {
+	switch obj := object.(type) {
+	case *appsv1.Deployment:
+		return c.deployment(*obj)
 	case *appsv1.StatefulSet:
-		return statefulSet(*obj), nil
+		return c.statefulSet(*obj)
 	case *appsv1.DaemonSet:
-		return daemonSet(*obj), nil
+		return c.daemonSet(*obj)
+	case *appsv1.ReplicaSet:
+		return c.replicaSet(*obj), nil
 	case *batchV1.Job:
-		return job(*obj), nil
+		return c.job(*obj), nil
 	case *batchV1.CronJob:
-		return cronjob(*obj), nil
+		return c.cronjob(*obj)
 	case *v1.Pod:
-		return pod(*obj), nil
+		return c.pod(*obj), nil
+	case *v1.PersistentVolumeClaim:
+		return c.persistentVolumeClaim(*obj), nil
+	case *v1.ReplicationController:
+		return c.replicationController(*obj), nil
+	case *scaledJob:
+		return c.scaledJob(*obj), nil
 	default:
-		return nil, CalculationError{
-			Version: version,
-			Kind:    kind,
-			err:     ErrResourceNotSupported,
+		return nil, ErrResourceNotSupported
+	}
+}
+
+// objectName returns the name of a k8s object.
+func objectName(object runtime.Object) string {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return ""
+	}
+
+	return accessor.GetName()
+}
+
+// podSpec returns the spec of the pods a k8s resource creates or nil, if the resource does not create
+// any pods.
+func podSpec(object runtime.Object) *v1.PodSpec {
+	switch obj := object.(type) {
+	case *appsv1.Deployment:
+		return &obj.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		return &obj.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		return &obj.Spec.Template.Spec
+	case *appsv1.ReplicaSet:
+		return &obj.Spec.Template.Spec
+	case *v1.ReplicationController:
+		if obj.Spec.Template == nil {
+			return nil
 		}
+
+		return &obj.Spec.Template.Spec
+	case *batchV1.Job:
+		return &obj.Spec.Template.Spec
+	case *batchV1.CronJob:
+		return &obj.Spec.JobTemplate.Spec.Template.Spec
+	case *v1.Pod:
+		return &obj.Spec
+	case *scaledJob:
+		if obj.Spec.JobTargetRef == nil {
+			return nil
+		}
+
+		return &obj.Spec.JobTargetRef.Template.Spec
+	default:
+		return nil
 	}
 }
+
+// admit applies the same changes to a pod spec as the admission plugins would do, when a pod is created.
+func (c *Calculator) admit(spec *v1.PodSpec) {
+	c.setLimitRangeDefaults(spec)
+	c.setOverhead(spec)
+}
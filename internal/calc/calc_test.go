@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var unsupportedOpenshiftRoute = `---
@@ -346,6 +348,54 @@ spec:
       securityContext: {}
       terminationGracePeriodSeconds: 30`
 
+var ephemeralStorageDeployment = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: storage
+  name: storage
+spec:
+  replicas: 4
+  selector:
+    matchLabels:
+      app: storage
+  strategy:
+    rollingUpdate:
+      maxSurge: 1
+      maxUnavailable: 0
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        app: storage
+    spec:
+      containers:
+        - image: myapp:v1.0.7
+          name: storage
+          resources:
+            limits:
+              cpu: '1'
+              memory: 4Gi
+              ephemeral-storage: 2Gi
+            requests:
+              cpu: '250m'
+              memory: 2Gi
+              ephemeral-storage: 1Gi
+          volumeMounts:
+            - name: cache
+              mountPath: /cache
+            - name: tmp
+              mountPath: /tmp
+      volumes:
+        - name: cache
+          emptyDir:
+            sizeLimit: 512Mi
+        - name: tmp
+          emptyDir:
+            medium: Memory
+            sizeLimit: 64Mi`
+
 var normalStatefulSet = `
 ---
 apiVersion: apps/v1
@@ -381,7 +431,7 @@ spec:
             memory: 2Gi
       terminationGracePeriodSeconds: 30`
 
-var noReplicasStatefulSet = `
+var fractionalCPUStatefulSet = `
 ---
 apiVersion: apps/v1
 kind: StatefulSet
@@ -390,7 +440,7 @@ metadata:
     app: myapp
   name: myapp
 spec:
-  revisionHistoryLimit: 1
+  replicas: 3
   selector:
     matchLabels:
       app: myapp
@@ -404,7 +454,42 @@ spec:
     spec:
       containers:
       - image: myapp
-        imagePullPolicy: Always
+        name: myapp
+        resources:
+          limits:
+            cpu: 500m
+            memory: 1.5Gi
+          requests:
+            cpu: 333m
+            memory: 100.5Mi`
+
+var partitionStatefulSet = `
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  labels:
+    app: myapp
+  name: myapp
+spec:
+  replicas: 5
+  selector:
+    matchLabels:
+      app: myapp
+  podManagementPolicy: Parallel
+  updateStrategy:
+    type: RollingUpdate
+    rollingUpdate:
+      partition: 2
+      maxUnavailable: 40%
+  serviceName: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      containers:
+      - image: myapp
         name: myapp
         resources:
           limits:
@@ -412,131 +497,1294 @@ spec:
             memory: 4Gi
           requests:
             cpu: 250m
-            memory: 2Gi
-      terminationGracePeriodSeconds: 30`
+            memory: 2Gi`
 
-var service = `
+var onDeleteStatefulSet = `
 ---
-apiVersion: v1
-kind: Service
+apiVersion: apps/v1
+kind: StatefulSet
 metadata:
-  name: myservice
+  labels:
+    app: myapp
+  name: myapp
 spec:
-  ports:
-  - name: grpc
-    port: 8080
-    protocol: TCP
-    targetPort: 8080
+  replicas: 5
   selector:
-    app: myapp
-  sessionAffinity: None
-  type: ClusterIP`
+    matchLabels:
+      app: myapp
+  updateStrategy:
+    type: OnDelete
+  serviceName: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
 
-var normalJob = `
+var invalidMaxUnavailableStatefulSet = `
 ---
-apiVersion: batch/v1
-kind: Job
+apiVersion: apps/v1
+kind: StatefulSet
 metadata:
-  name: pi
+  labels:
+    app: myapp
+  name: myapp
 spec:
+  replicas: 5
+  selector:
+    matchLabels:
+      app: myapp
+  updateStrategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxUnavailable: 0
+  serviceName: myapp
   template:
+    metadata:
+      labels:
+        app: myapp
     spec:
       containers:
-        - name: pi
-          image: alpine
-          resources:
-            limits:
-              cpu: "1"
-              memory: 4Gi
-            requests:
-              cpu: 250m
-              memory: 2Gi
-      restartPolicy: Never
-  backoffLimit: 4`
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
 
-var normalCronJob =`---
-apiVersion: batch/v1
-kind: CronJob
+var noReplicasStatefulSet = `
+---
+apiVersion: apps/v1
+kind: StatefulSet
 metadata:
-  name: hello
+  labels:
+    app: myapp
+  name: myapp
 spec:
-  schedule: "*/1 * * * *"
-  jobTemplate:
+  revisionHistoryLimit: 1
+  selector:
+    matchLabels:
+      app: myapp
+  updateStrategy:
+    type: RollingUpdate
+  serviceName: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
     spec:
-      template:
-        spec:
-          containers:
-            - name: hello
-              image: busybox
-              resources:
-                limits:
-                  cpu: "1"
-                  memory: 4Gi
-                requests:
-                  cpu: 250m
-                  memory: 2Gi
-              imagePullPolicy: IfNotPresent
-          restartPolicy: OnFailure`
+      containers:
+      - image: myapp
+        imagePullPolicy: Always
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi
+      terminationGracePeriodSeconds: 30`
 
-var normalPod = `
+var volumeClaimTemplateStatefulSet = `
 ---
-apiVersion: v1
-kind: Pod
+apiVersion: apps/v1
+kind: StatefulSet
 metadata:
   labels:
-    app: mypod
-  name: mypod
+    app: myapp
+  name: myapp
 spec:
-  containers:
-  - image: mypod
-    imagePullPolicy: Always
-    name: myapp
-    resources:
-      limits:
-        cpu: "1"
-        memory: 4Gi
-      requests:
-        cpu: 250m
-        memory: 2Gi
-  terminationGracePeriodSeconds: 30`
+  replicas: 3
+  selector:
+    matchLabels:
+      app: myapp
+  serviceName: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: 500m
+            memory: 1Gi
+        volumeMounts:
+        - name: data
+          mountPath: /data
+        - name: logs
+          mountPath: /logs
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      storageClassName: gold
+      resources:
+        requests:
+          storage: 10Gi
+  - metadata:
+      name: logs
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      resources:
+        requests:
+          storage: 1Gi`
 
-var  normalDaemonSet = `
+var normalReplicaSet = `
+---
 apiVersion: apps/v1
-kind: DaemonSet
+kind: ReplicaSet
 metadata:
-  name: mydaemonset
   labels:
-    app: mydaemonset
+    app: myapp
+  name: myapp
 spec:
+  replicas: 3
   selector:
     matchLabels:
-      name: mydaemonset
+      app: myapp
   template:
     metadata:
       labels:
-        name: mydaemonset
+        app: myapp
     spec:
       containers:
-      - name: mydaemonset
-        image: quay.io/fluentd_elasticsearch/fluentd:v2.5.2
+      - image: myapp
+        name: myapp
         resources:
           limits:
-            memory: 2Gi 
-            cpu: "2"
+            cpu: "1"
+            memory: 4Gi
           requests:
-            cpu: 500m
-            memory: 200Mi
-      terminationGracePeriodSeconds: 30`
-
-func TestResourceQuotaFromYaml(t *testing.T) {
-	r := require.New(t)
-
-	usage, err := ResourceQuotaFromYaml([]byte(service))
-	r.Error(err)
-	r.True(errors.Is(err, ErrResourceNotSupported))
-	r.Nil(usage)
+            cpu: 250m
+            memory: 2Gi`
 
-	var calcErr CalculationError
+var noReplicasReplicaSet = `
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  labels:
+    app: myapp
+  name: myapp
+spec:
+  selector:
+    matchLabels:
+      app: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var normalReplicationController = `
+---
+apiVersion: v1
+kind: ReplicationController
+metadata:
+  labels:
+    app: myapp
+  name: myapp
+spec:
+  replicas: 2
+  selector:
+    app: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var noReplicasReplicationController = `
+---
+apiVersion: v1
+kind: ReplicationController
+metadata:
+  labels:
+    app: myapp
+  name: myapp
+spec:
+  selector:
+    app: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var noTemplateReplicationController = `
+---
+apiVersion: v1
+kind: ReplicationController
+metadata:
+  name: myapp
+spec:
+  replicas: 2`
+
+var extensionsDeployment = `
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: legacy
+spec:
+  replicas: 10
+  template:
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var appsV1beta2Deployment = `
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: legacy
+spec:
+  replicas: 10
+  template:
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var appsV1beta1StatefulSet = `
+---
+apiVersion: apps/v1beta1
+kind: StatefulSet
+metadata:
+  name: legacy
+spec:
+  replicas: 10
+  template:
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var extensionsDaemonSet = `
+---
+apiVersion: extensions/v1beta1
+kind: DaemonSet
+metadata:
+  name: legacy
+spec:
+  template:
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var appsV1beta2ReplicaSet = `
+---
+apiVersion: apps/v1beta2
+kind: ReplicaSet
+metadata:
+  name: legacy
+spec:
+  replicas: 10
+  template:
+    spec:
+      containers:
+      - image: myapp
+        name: myapp
+        resources:
+          limits:
+            cpu: "1"
+            memory: 4Gi
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+var batchV1beta1CronJob = `
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: legacy
+spec:
+  schedule: "*/1 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: myapp
+              image: myapp
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var normalPersistentVolumeClaim = `
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: mypvc
+spec:
+  accessModes:
+    - ReadWriteOnce
+  storageClassName: gold
+  resources:
+    requests:
+      storage: 8Gi`
+
+var annotatedPersistentVolumeClaim = `
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: mypvc
+  annotations:
+    volume.beta.kubernetes.io/storage-class: silver
+spec:
+  accessModes:
+    - ReadWriteOnce
+  storageClassName: gold
+  resources:
+    requests:
+      storage: 8Gi`
+
+var noStorageClassPersistentVolumeClaim = `
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: mypvc
+spec:
+  accessModes:
+    - ReadWriteOnce
+  resources:
+    requests:
+      storage: 8Gi`
+
+var service = `
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: myservice
+spec:
+  ports:
+  - name: grpc
+    port: 8080
+    protocol: TCP
+    targetPort: 8080
+  selector:
+    app: myapp
+  sessionAffinity: None
+  type: ClusterIP`
+
+var loadBalancerService = `
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: myservice
+spec:
+  ports:
+  - name: http
+    port: 80
+    protocol: TCP
+    targetPort: 8080
+  - name: https
+    port: 443
+    protocol: TCP
+    targetPort: 8443
+  selector:
+    app: myapp
+  type: LoadBalancer`
+
+var configMap = `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myconfig
+data:
+  key: value`
+
+var secret = `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: mysecret
+type: Opaque
+stringData:
+  password: secret`
+
+var normalJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pi
+spec:
+  template:
+    spec:
+      containers:
+        - name: pi
+          image: alpine
+          resources:
+            limits:
+              cpu: "1"
+              memory: 4Gi
+            requests:
+              cpu: 250m
+              memory: 2Gi
+      restartPolicy: Never
+  backoffLimit: 4`
+
+var parallelJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pi
+spec:
+  parallelism: 10
+  completions: 4
+  template:
+    spec:
+      containers:
+        - name: pi
+          image: alpine
+          resources:
+            limits:
+              cpu: "1"
+              memory: 4Gi
+            requests:
+              cpu: 250m
+              memory: 2Gi
+      restartPolicy: Never`
+
+var workQueueJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pi
+spec:
+  parallelism: 5
+  template:
+    spec:
+      containers:
+        - name: pi
+          image: alpine
+          resources:
+            limits:
+              cpu: "1"
+              memory: 4Gi
+            requests:
+              cpu: 250m
+              memory: 2Gi
+      restartPolicy: Never`
+
+var indexedJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pi
+spec:
+  parallelism: 10
+  completions: 3
+  completionMode: Indexed
+  template:
+    spec:
+      containers:
+        - name: pi
+          image: alpine
+          resources:
+            limits:
+              cpu: "1"
+              memory: 4Gi
+            requests:
+              cpu: 250m
+              memory: 2Gi
+      restartPolicy: Never`
+
+var normalCronJob =`---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "*/1 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: hello
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+              imagePullPolicy: IfNotPresent
+          restartPolicy: OnFailure`
+
+var overlappingCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "*/10 * * * *"
+  jobTemplate:
+    spec:
+      parallelism: 2
+      activeDeadlineSeconds: 1800
+      template:
+        spec:
+          containers:
+            - name: hello
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var forbidCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "*/10 * * * *"
+  concurrencyPolicy: Forbid
+  jobTemplate:
+    spec:
+      parallelism: 2
+      activeDeadlineSeconds: 1800
+      template:
+        spec:
+          containers:
+            - name: hello
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var timeZoneCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "30 2 * * *"
+  timeZone: Europe/Zurich
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 7200
+      template:
+        spec:
+          containers:
+            - name: hello
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var invalidScheduleCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "every minute"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: hello
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var backupCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: "0 2 * * *"
+  timeZone: Europe/Zurich
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+              image: busybox
+              resources:
+                requests:
+                  cpu: "1"
+                  memory: 1Gi
+          restartPolicy: OnFailure`
+
+var cleanupCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  schedule: "30 1 * * 1"
+  jobTemplate:
+    spec:
+      parallelism: 2
+      template:
+        spec:
+          containers:
+            - name: cleanup
+              image: busybox
+              resources:
+                requests:
+                  cpu: 500m
+                  memory: 1Gi
+          restartPolicy: OnFailure`
+
+var reportCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  schedule: "0 3 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: report
+              image: busybox
+              resources:
+                requests:
+                  cpu: "2"
+          restartPolicy: OnFailure`
+
+var monthlyCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: invoice
+spec:
+  schedule: "0 3 15 * *"
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 3600
+      template:
+        spec:
+          containers:
+            - name: invoice
+              image: busybox
+              resources:
+                requests:
+                  cpu: "4"
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var archiveCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: archive
+spec:
+  schedule: "30 1 3 * *"
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 3600
+      template:
+        spec:
+          containers:
+            - name: archive
+              image: busybox
+              resources:
+                requests:
+                  cpu: "1"
+                  memory: 1Gi
+          restartPolicy: OnFailure`
+
+var monthlyOverlappingCronJob = `
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "*/10 * 15 * *"
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 1800
+      template:
+        spec:
+          containers:
+            - name: hello
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var normalPod = `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    app: mypod
+  name: mypod
+spec:
+  containers:
+  - image: mypod
+    imagePullPolicy: Always
+    name: myapp
+    resources:
+      limits:
+        cpu: "1"
+        memory: 4Gi
+      requests:
+        cpu: 250m
+        memory: 2Gi
+  terminationGracePeriodSeconds: 30`
+
+var  normalDaemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: mydaemonset
+  labels:
+    app: mydaemonset
+spec:
+  selector:
+    matchLabels:
+      name: mydaemonset
+  template:
+    metadata:
+      labels:
+        name: mydaemonset
+    spec:
+      containers:
+      - name: mydaemonset
+        image: quay.io/fluentd_elasticsearch/fluentd:v2.5.2
+        resources:
+          limits:
+            memory: 2Gi 
+            cpu: "2"
+          requests:
+            cpu: 500m
+            memory: 200Mi
+      terminationGracePeriodSeconds: 30`
+
+var workerNodeA = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-a
+  labels:
+    role: "worker"
+    zone: "a"`
+
+var workerNodeB = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-b
+  labels:
+    role: "worker"
+    zone: "b"`
+
+var gpuNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: gpu
+  labels:
+    role: "worker"
+    gpu: "true"
+spec:
+  taints:
+  - key: gpu
+    value: "true"
+    effect: NoSchedule`
+
+var controlPlaneNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: control-plane
+  labels:
+    role: "control-plane"
+spec:
+  taints:
+  - key: node-role.kubernetes.io/control-plane
+    effect: NoSchedule`
+
+var preferNoScheduleNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: prefer
+  labels:
+    role: "worker"
+spec:
+  taints:
+  - key: maintenance
+    value: "true"
+    effect: PreferNoSchedule`
+
+var notReadyNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: not-ready
+  labels:
+    role: "worker"
+spec:
+  taints:
+  - key: node.kubernetes.io/not-ready
+    effect: NoExecute`
+
+var nodeList = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: worker-a
+    labels:
+      role: worker
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: worker-b
+    labels:
+      role: worker`
+
+var selectorDaemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: selector
+spec:
+  selector:
+    matchLabels:
+      name: selector
+  updateStrategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 0
+  template:
+    metadata:
+      labels:
+        name: selector
+    spec:
+      nodeSelector:
+        role: worker
+      tolerations:
+      - key: gpu
+        operator: Exists
+      containers:
+      - name: selector
+        image: selector
+        resources:
+          requests:
+            cpu: 100m
+            memory: 100Mi`
+
+var affinityDaemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: affinity
+spec:
+  selector:
+    matchLabels:
+      name: affinity
+  template:
+    metadata:
+      labels:
+        name: affinity
+    spec:
+      affinity:
+        nodeAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            nodeSelectorTerms:
+            - matchExpressions:
+              - key: zone
+                operator: In
+                values: ["a"]
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: affinity
+        image: affinity
+        resources:
+          requests:
+            cpu: 100m
+            memory: 100Mi`
+
+var onDeleteDaemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ondelete
+spec:
+  selector:
+    matchLabels:
+      name: ondelete
+  updateStrategy:
+    type: OnDelete
+  template:
+    metadata:
+      labels:
+        name: ondelete
+    spec:
+      containers:
+      - name: ondelete
+        image: ondelete
+        resources:
+          requests:
+            cpu: 100m
+            memory: 100Mi`
+
+var kataRuntimeClass = `
+---
+apiVersion: node.k8s.io/v1
+kind: RuntimeClass
+metadata:
+  name: kata
+handler: kata
+overhead:
+  podFixed:
+    cpu: 250m
+    memory: 128Mi`
+
+var runtimeClassPod = `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    app: mypod
+  name: mypod
+spec:
+  runtimeClassName: kata
+  containers:
+  - image: mypod
+    imagePullPolicy: Always
+    name: myapp
+    resources:
+      limits:
+        cpu: "1"
+      requests:
+        cpu: 250m
+        memory: 2Gi
+  terminationGracePeriodSeconds: 30`
+
+var overheadPod = `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    app: mypod
+  name: mypod
+spec:
+  runtimeClassName: kata
+  overhead:
+    cpu: 100m
+    memory: 64Mi
+  containers:
+  - image: mypod
+    imagePullPolicy: Always
+    name: myapp
+    resources:
+      limits:
+        cpu: "1"
+        memory: 4Gi
+      requests:
+        cpu: 250m
+        memory: 2Gi
+  terminationGracePeriodSeconds: 30`
+
+var defaultLimitRange = `
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: defaults
+spec:
+  limits:
+    - type: Container
+      default:
+        cpu: 500m
+        memory: 512Mi
+      defaultRequest:
+        cpu: 100m
+        memory: 256Mi`
+
+var maxLimitRange = `
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: max
+spec:
+  limits:
+    - type: Container
+      max:
+        cpu: "2"
+        memory: 1Gi
+      min:
+        cpu: 10m
+    - type: PersistentVolumeClaim
+      max:
+        storage: 10Gi`
+
+var noResourcesPod = `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: noresources
+spec:
+  containers:
+  - image: myapp
+    name: myapp
+  - image: log
+    name: log
+    resources:
+      requests:
+        cpu: 50m`
+
+var limitsOnlyPod = `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: limitsonly
+spec:
+  containers:
+  - image: myapp
+    name: myapp
+    resources:
+      limits:
+        cpu: "1"
+        memory: 1Gi
+      requests:
+        cpu: 200m`
+
+var priorityJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: cleanup
+spec:
+  template:
+    spec:
+      activeDeadlineSeconds: 600
+      priorityClassName: high
+      containers:
+        - name: cleanup
+          image: alpine
+      restartPolicy: Never`
+
+var deadlineJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: report
+spec:
+  activeDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+        - name: report
+          image: alpine
+          resources:
+            requests:
+              cpu: 100m
+      restartPolicy: Never`
+
+var computeResourceQuota = `
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: compute
+spec:
+  hard:
+    requests.cpu: 3500m
+    limits.cpu: "6"
+    memory: 28Gi
+    pods: "14"
+    configmaps: "1"`
+
+var terminatingResourceQuota = `
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: terminating
+spec:
+  scopes:
+    - Terminating
+  hard:
+    pods: "0"`
+
+var priorityClassResourceQuota = `
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: high
+spec:
+  scopeSelector:
+    matchExpressions:
+      - scopeName: PriorityClass
+        operator: NotIn
+        values:
+          - high
+  hard:
+    requests.cpu: "2"`
+
+func TestResourceQuotaFromYaml(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(service))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+	r.Nil(usage)
+
+	var calcErr CalculationError
 
 	r.True(errors.As(err, &calcErr))
 	r.Equal("calculating v1/Service resource usage: resource not supported", calcErr.Error())
@@ -549,3 +1797,464 @@ func TestResourceQuotaFromYaml(t *testing.T) {
 	r.True(errors.As(err,&calcErr))
 }
 
+// quantity returns the quantity of a ResourceQuota resource name from usage.
+func quantity(usage *ResourceUsage, name v1.ResourceName) *resource.Quantity {
+	return usage.Resources.Name(name, resource.DecimalSI)
+}
+
+func container(name, cpu, memory string) v1.Container {
+	return v1.Container{
+		Name: name,
+		Resources: v1.ResourceRequirements{
+			Limits: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func sidecar(name, cpu, memory string) v1.Container {
+	restartPolicy := v1.ContainerRestartPolicyAlways
+
+	c := container(name, cpu, memory)
+	c.RestartPolicy = &restartPolicy
+
+	return c
+}
+
+func TestPodResources(t *testing.T) {
+	var tests = []struct {
+		name    string
+		podSpec v1.PodSpec
+		cpu     resource.Quantity
+		memory  resource.Quantity
+	}{
+		{
+			name: "app containers only",
+			podSpec: v1.PodSpec{
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+					container("sidecar", "100m", "128Mi"),
+				},
+			},
+			cpu:    resource.MustParse("600m"),
+			memory: resource.MustParse("1152Mi"),
+		},
+		{
+			name: "init container smaller than app containers",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					container("init", "100m", "64Mi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+					container("sidecar", "100m", "128Mi"),
+				},
+			},
+			cpu:    resource.MustParse("600m"),
+			memory: resource.MustParse("1152Mi"),
+		},
+		{
+			name: "init container bigger than app containers",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					container("init", "2", "4Gi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+					container("sidecar", "100m", "128Mi"),
+				},
+			},
+			cpu:    resource.MustParse("2"),
+			memory: resource.MustParse("4Gi"),
+		},
+		{
+			name: "init container with bigger cpu and smaller memory",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					container("init", "1", "256Mi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+				},
+			},
+			cpu:    resource.MustParse("1"),
+			memory: resource.MustParse("1Gi"),
+		},
+		{
+			name: "multiple init containers",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					container("init-1", "1", "256Mi"),
+					container("init-2", "750m", "2Gi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+				},
+			},
+			cpu:    resource.MustParse("1"),
+			memory: resource.MustParse("2Gi"),
+		},
+		{
+			name: "sidecar container",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					sidecar("proxy", "100m", "128Mi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+				},
+			},
+			cpu:    resource.MustParse("600m"),
+			memory: resource.MustParse("1152Mi"),
+		},
+		{
+			name: "init container started after sidecar container",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					sidecar("proxy", "100m", "128Mi"),
+					container("init", "2", "4Gi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+				},
+			},
+			cpu:    resource.MustParse("2100m"),
+			memory: resource.MustParse("4224Mi"),
+		},
+		{
+			name: "init container started before sidecar container",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					container("init", "2", "4Gi"),
+					sidecar("proxy", "100m", "128Mi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+				},
+			},
+			cpu:    resource.MustParse("2"),
+			memory: resource.MustParse("4Gi"),
+		},
+		{
+			name: "multiple sidecar containers",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					sidecar("proxy", "100m", "128Mi"),
+					container("init", "500m", "512Mi"),
+					sidecar("log-shipper", "200m", "256Mi"),
+				},
+				Containers: []v1.Container{
+					container("app", "500m", "1Gi"),
+				},
+			},
+			cpu:    resource.MustParse("800m"),
+			memory: resource.MustParse("1408Mi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			resources := NewCalculator().podResources(&test.podSpec)
+
+			r.Equalf(test.cpu.MilliValue(), resources.Name(v1.ResourceRequestsCPU, resource.DecimalSI).MilliValue(), "cpu requests value")
+			r.Equalf(test.memory.Value(), resources.Name(v1.ResourceRequestsMemory, resource.DecimalSI).Value(), "memory requests value")
+			r.Equalf(test.cpu.MilliValue(), resources.Name(v1.ResourceLimitsCPU, resource.DecimalSI).MilliValue(), "cpu limits value")
+			r.Equalf(test.memory.Value(), resources.Name(v1.ResourceLimitsMemory, resource.DecimalSI).Value(), "memory limits value")
+		})
+	}
+}
+
+func TestPodResourcesExtendedResources(t *testing.T) {
+	r := require.New(t)
+
+	gpu := container("gpu", "1", "1Gi")
+	gpu.Resources.Limits["nvidia.com/gpu"] = resource.MustParse("2")
+	gpu.Resources.Requests["nvidia.com/gpu"] = resource.MustParse("2")
+	gpu.Resources.Limits["hugepages-2Mi"] = resource.MustParse("100Mi")
+	gpu.Resources.Requests["hugepages-2Mi"] = resource.MustParse("100Mi")
+
+	resources := NewCalculator().podResources(&v1.PodSpec{
+		Containers: []v1.Container{
+			gpu,
+			container("app", "500m", "1Gi"),
+		},
+	})
+
+	r.Len(resources, 6)
+	r.Equal(int64(2), resources.Name("requests.nvidia.com/gpu", resource.DecimalSI).Value())
+	r.Equal(int64(100*1024*1024), resources.Name("requests.hugepages-2Mi", resource.BinarySI).Value())
+	r.Equal(int64(1500), resources.Name(v1.ResourceLimitsCPU, resource.DecimalSI).MilliValue())
+	r.NotContains(resources, v1.ResourceName("limits.nvidia.com/gpu"))
+	r.NotContains(resources, v1.ResourceName("limits.hugepages-2Mi"))
+}
+
+func TestPodResourcesDefaultRequests(t *testing.T) {
+	r := require.New(t)
+
+	initContainer := container("init", "2", "1Gi")
+	initContainer.Resources.Requests = nil
+
+	app := container("app", "1", "2Gi")
+	delete(app.Resources.Requests, v1.ResourceMemory)
+	app.Resources.Requests[v1.ResourceCPU] = resource.MustParse("250m")
+
+	podSpec := v1.PodSpec{
+		InitContainers: []v1.Container{initContainer},
+		Containers:     []v1.Container{app},
+	}
+
+	resources := NewCalculator().podResources(&podSpec)
+
+	r.Equalf(int64(2000), resources.Name(v1.ResourceRequestsCPU, resource.DecimalSI).MilliValue(), "cpu requests value")
+	r.Equalf(int64(2*1024*1024*1024), resources.Name(v1.ResourceRequestsMemory, resource.DecimalSI).Value(), "memory requests value")
+	r.Equalf(int64(2000), resources.Name(v1.ResourceLimitsCPU, resource.DecimalSI).MilliValue(), "cpu limits value")
+	r.Equalf(int64(2*1024*1024*1024), resources.Name(v1.ResourceLimitsMemory, resource.DecimalSI).Value(), "memory limits value")
+
+	// the container specs are not modified
+	r.Len(podSpec.Containers[0].Resources.Requests, 1)
+	r.Nil(podSpec.InitContainers[0].Resources.Requests)
+}
+
+func TestResourceUsageContainers(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(initContainerDeployment))
+	r.NoError(err)
+	r.Len(usage.Containers, 2)
+
+	initContainer := usage.Containers[0]
+	r.Equal("myinit", initContainer.Name)
+	r.True(initContainer.Init)
+	r.False(initContainer.Sidecar)
+	r.Equal(int64(100), initContainer.Resources.Name(v1.ResourceRequestsCPU, resource.DecimalSI).MilliValue())
+	r.Equal(int64(100), initContainer.Resources.Name(v1.ResourceLimitsCPU, resource.DecimalSI).MilliValue())
+
+	app := usage.Containers[1]
+	r.Equal("normal", app.Name)
+	r.False(app.Init)
+	r.Equal(int64(250), app.Resources.Name(v1.ResourceRequestsCPU, resource.DecimalSI).MilliValue())
+	r.Equal(int64(1000), app.Resources.Name(v1.ResourceLimitsCPU, resource.DecimalSI).MilliValue())
+
+	r.Equal(int64(250), usage.PodResources.Name(v1.ResourceRequestsCPU, resource.DecimalSI).MilliValue())
+	r.Equal(int64(1000), usage.PodResources.Name(v1.ResourceLimitsCPU, resource.DecimalSI).MilliValue())
+	r.Equal(int64(1000), quantity(usage, v1.ResourceRequestsCPU).MilliValue())
+
+	usage, err = ResourceQuotaFromYaml([]byte(normalPersistentVolumeClaim))
+	r.NoError(err)
+	r.Nil(usage.PodResources)
+	r.Empty(usage.Containers)
+}
+
+func TestMulQuantity(t *testing.T) {
+	var tests = []struct {
+		name        string
+		quantity    string
+		numerator   int64
+		denominator int64
+		expected    string
+	}{
+		{
+			name:        "fractional cpu",
+			quantity:    "500m",
+			numerator:   3,
+			denominator: 1,
+			expected:    "1500m",
+		},
+		{
+			name:        "rounded up to milli units",
+			quantity:    "1n",
+			numerator:   3,
+			denominator: 1,
+			expected:    "1m",
+		},
+		{
+			name:        "percent",
+			quantity:    "333m",
+			numerator:   110,
+			denominator: 100,
+			expected:    "367m",
+		},
+		{
+			name:        "binary quantity",
+			quantity:    "1.5Gi",
+			numerator:   3,
+			denominator: 1,
+			expected:    "4.5Gi",
+		},
+		{
+			name:        "intermediate result exceeding int64",
+			quantity:    "4Ei",
+			numerator:   3,
+			denominator: 4,
+			expected:    "3Ei",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			result := mulQuantity(resource.MustParse(test.quantity), test.numerator, test.denominator)
+			expected := resource.MustParse(test.expected)
+
+			r.Truef(expected.Equal(result), "expected %s, got %s", expected.String(), result.String())
+		})
+	}
+}
+
+var normalHorizontalPodAutoscaler = `
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: normal
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: normal
+  minReplicas: 2
+  maxReplicas: 30
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 80`
+
+var statefulSetHorizontalPodAutoscaler = `
+---
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: myapp
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: StatefulSet
+    name: myapp
+  maxReplicas: 5
+  targetCPUUtilizationPercentage: 80`
+
+var zeroReplicaHorizontalPodAutoscaler = `
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: zero
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: zero
+  maxReplicas: 10`
+
+var zeroReplicaScaledObject = `
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: zero
+spec:
+  scaleTargetRef:
+    name: zero
+  minReplicaCount: 0
+  maxReplicaCount: 8
+  triggers:
+    - type: rabbitmq
+      metadata:
+        queueName: orders
+        mode: QueueLength
+        value: "20"`
+
+var statefulSetScaledObject = `
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: myapp
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: StatefulSet
+    name: myapp
+  triggers:
+    - type: cpu
+      metricType: Utilization
+      metadata:
+        value: "80"`
+
+var normalScaledJob = `
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledJob
+metadata:
+  name: worker
+spec:
+  jobTargetRef:
+    parallelism: 2
+    completions: 4
+    activeDeadlineSeconds: 600
+    template:
+      spec:
+        restartPolicy: Never
+        containers:
+          - name: worker
+            image: worker
+            resources:
+              limits:
+                cpu: 500m
+                memory: 1Gi
+              requests:
+                cpu: 250m
+                memory: 512Mi
+  maxReplicaCount: 5
+  scalingStrategy:
+    strategy: accurate
+  triggers:
+    - type: rabbitmq
+      metadata:
+        queueName: jobs
+        value: "1"`
+
+var defaultScaledJob = `
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledJob
+metadata:
+  name: worker
+spec:
+  jobTargetRef:
+    template:
+      spec:
+        restartPolicy: Never
+        containers:
+          - name: worker
+            image: worker
+            resources:
+              limits:
+                cpu: 100m
+                memory: 128Mi
+  triggers:
+    - type: rabbitmq
+      metadata:
+        queueName: jobs
+        value: "1"`
+
+var noTemplateScaledJob = `
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledJob
+metadata:
+  name: worker
+spec:
+  maxReplicaCount: 5`
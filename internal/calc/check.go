@@ -0,0 +1,94 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var (
+	// ErrQuotaExceeded is returned if the resource usage exceeds the hard limits of a ResourceQuota.
+	ErrQuotaExceeded = errors.New("resource quota exceeded")
+)
+
+// QuotaStatus compares the used resources with the hard limit of a single resource of a ResourceQuota.
+type QuotaStatus struct {
+	Name      v1.ResourceName
+	Used      resource.Quantity
+	Hard      resource.Quantity
+	Remaining resource.Quantity
+}
+
+// Exceeded returns true, if more resources are used than the hard limit allows.
+func (s QuotaStatus) Exceeded() bool {
+	return s.Used.Cmp(s.Hard) > 0
+}
+
+// DecodeResourceQuota decodes a single yaml document containing a ResourceQuota.
+func DecodeResourceQuota(yamlData []byte) (*v1.ResourceQuota, error) {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	quota, ok := object.(*v1.ResourceQuota)
+	if !ok {
+		return nil, CalculationError{
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+			err:     ErrResourceNotSupported,
+		}
+	}
+
+	return quota, nil
+}
+
+// CheckResourceQuota compares the summed resource usage with all hard limits of a ResourceQuota. Only
+// resource usages, which match the scopes of the quota, are taken into account. The result is sorted
+// by resource name.
+func CheckResourceQuota(quota *v1.ResourceQuota, usage []*ResourceUsage) []QuotaStatus {
+	used := v1.ResourceList{}
+	selectors := scopeSelectors(quota)
+
+	for _, u := range usage {
+		if matchesScopes(selectors, &u.Details) {
+			addResourceList(used, scopedResources(selectors, u.Resources))
+		}
+	}
+
+	status := make([]QuotaStatus, 0, len(quota.Spec.Hard))
+
+	for name, hard := range quota.Spec.Hard {
+		s := QuotaStatus{
+			Name: name,
+			Used: used[quotaUsageName(name)].DeepCopy(),
+			Hard: hard.DeepCopy(),
+		}
+
+		s.Remaining = s.Hard.DeepCopy()
+		s.Remaining.Sub(s.Used)
+
+		status = append(status, s)
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Name < status[j].Name
+	})
+
+	return status
+}
+
+// quotaUsageName returns the name of the resource usage, which is limited by a hard limit of a
+// ResourceQuota. cpu, memory and ephemeral-storage are the same as their requests.
+func quotaUsageName(name v1.ResourceName) v1.ResourceName {
+	switch name {
+	case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+		return v1.ResourceName(v1.DefaultResourceRequestsPrefix + name)
+	default:
+		return name
+	}
+}
@@ -0,0 +1,96 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCheckResourceQuota(t *testing.T) {
+	calculator := NewCalculator()
+	calculator.ObjectCounts = true
+
+	var usage []*ResourceUsage
+
+	for _, manifest := range []string{normalDeployment, normalJob, configMap} {
+		u, err := calculator.ResourceQuotaFromYaml([]byte(manifest))
+		require.NoError(t, err)
+
+		usage = append(usage, u)
+	}
+
+	var tests = []struct {
+		name     string
+		quota    string
+		used     map[v1.ResourceName]string
+		exceeded []v1.ResourceName
+	}{
+		{
+			name:  "quota",
+			quota: computeResourceQuota,
+			used: map[v1.ResourceName]string{
+				v1.ResourceConfigMaps:  "1",
+				v1.ResourceLimitsCPU:   "7500m",
+				v1.ResourceMemory:      "28Gi",
+				v1.ResourcePods:        "14",
+				v1.ResourceRequestsCPU: "3500m",
+			},
+			exceeded: []v1.ResourceName{v1.ResourceLimitsCPU},
+		},
+		{
+			name:  "terminating scope",
+			quota: terminatingResourceQuota,
+			used: map[v1.ResourceName]string{
+				v1.ResourcePods: "0",
+			},
+		},
+		{
+			name:  "priority class scope selector",
+			quota: priorityClassResourceQuota,
+			used: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU: "3500m",
+			},
+			exceeded: []v1.ResourceName{v1.ResourceRequestsCPU},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			quota, err := DecodeResourceQuota([]byte(test.quota))
+			r.NoError(err)
+
+			status := CheckResourceQuota(quota, usage)
+			r.Len(status, len(test.used))
+
+			var exceeded []v1.ResourceName
+
+			for _, s := range status {
+				used, ok := test.used[s.Name]
+				r.True(ok, "unexpected resource %s", s.Name)
+				r.True(resource.MustParse(used).Equal(s.Used), "%s: expected %s, got %s", s.Name, used, s.Used.String())
+
+				remaining := s.Hard.DeepCopy()
+				remaining.Sub(s.Used)
+				r.True(remaining.Equal(s.Remaining))
+
+				if s.Exceeded() {
+					exceeded = append(exceeded, s.Name)
+				}
+			}
+
+			r.Equal(test.exceeded, exceeded)
+		})
+	}
+}
+
+func TestDecodeResourceQuota(t *testing.T) {
+	r := require.New(t)
+
+	_, err := DecodeResourceQuota([]byte(normalPod))
+	r.ErrorIs(err, ErrResourceNotSupported)
+}
@@ -1,22 +1,333 @@
 package calc
 
-import batchV1 "k8s.io/api/batch/v1"
+import (
+	"fmt"
+	"sort"
+	"time"
+	_ "time/tzdata" // the time zones of cronjobs must not depend on the system kuota-calc runs on
 
-func cronjob(cronjob batchV1.CronJob) *ResourceUsage {
-	cpu, memory := podResources(&cronjob.Spec.JobTemplate.Spec.Template.Spec)
+	"github.com/robfig/cron/v3"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// simulationPeriod is the period in which the schedules of cronjobs are simulated.
+	simulationPeriod = 7 * 24 * time.Hour
+)
+
+// CronJobSchedule contains the schedule of a cronjob and the resources of a single job, which are
+// needed to simulate when the jobs of multiple cronjobs run at the same time.
+type CronJobSchedule struct {
+	// Resources contains the resources of a single job.
+	Resources v1.ResourceList
+
+	pods     int32
+	schedule cron.Schedule
+	policy   batchV1.ConcurrencyPolicy
+	runtime  time.Duration
+}
+
+// Peak is the highest usage of a single resource and the first time it is reached.
+type Peak struct {
+	Name     v1.ResourceName
+	Quantity resource.Quantity
+	Time     time.Time
+}
+
+// jobRun is a single run of a job.
+type jobRun struct {
+	start, end time.Time
+}
+
+// calculates the resources a single cronjob needs. Every job can run multiple pods in parallel and with
+// the Allow concurrency policy, jobs overlap if they run longer than the time until their next
+// schedule.
+func (c *Calculator) cronjob(cronjob batchV1.CronJob) (*ResourceUsage, error) {
+	_, maxPods := jobPods(&cronjob.Spec.JobTemplate.Spec)
+
+	schedule, err := parseSchedule(&cronjob.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("cronjob: %s: %w", cronjob.Name, err)
+	}
+
+	jobSchedule := &CronJobSchedule{
+		Resources: c.podResources(&cronjob.Spec.JobTemplate.Spec.Template.Spec),
+		pods:      maxPods,
+		schedule:  schedule,
+		policy:    concurrencyPolicy(&cronjob.Spec),
+		runtime:   c.jobRuntime(cronjob.Name, &cronjob.Spec.JobTemplate.Spec),
+	}
+
+	mulResourceList(jobSchedule.Resources, int64(maxPods))
+
+	jobs := jobSchedule.concurrentJobs()
+
+	resources := jobSchedule.Resources.DeepCopy()
+	mulResourceList(resources, int64(jobs))
 
 	resourceUsage := ResourceUsage{
-		CPU:    cpu,
-		Memory: memory,
+		Resources: resources,
 		Details: Details{
 			Version:     cronjob.APIVersion,
 			Kind:        cronjob.Kind,
 			Name:        cronjob.Name,
-			Strategy:    "",
-			Replicas:    0,
-			MaxReplicas: 0,
+			Strategy:    string(jobSchedule.policy),
+			Replicas:    maxPods,
+			MaxReplicas: jobs * maxPods,
 		},
+		CronJob: jobSchedule,
+	}
+
+	return &resourceUsage, nil
+}
+
+// concurrentJobs returns the maximum number of jobs of a cronjob, which run at the same time. Only the
+// Allow concurrency policy lets jobs overlap, how many of them do depends on the runtime of the jobs.
+// Without a known runtime, every job is expected to finish before the next one is scheduled.
+func (s *CronJobSchedule) concurrentJobs() int32 {
+	if s.policy != batchV1.AllowConcurrent || s.runtime <= 0 {
+		return 1
+	}
+
+	start := simulationStart()
+
+	// schedules limited to certain days or months are simulated from their first run
+	if next := s.schedule.Next(start.Add(-time.Second)); s.restricted() && !next.IsZero() {
+		start = next
+	}
+
+	runs := s.runs(start, start.Add(simulationPeriod))
+
+	jobs := int32(1)
+
+	for i, j := 0, 0; j < len(runs); j++ {
+		// jobs which ended at or before the start of run j are not running anymore
+		for !runs[i].end.After(runs[j].start) {
+			i++
+		}
+
+		if running := int32(j - i + 1); running > jobs {
+			jobs = running
+		}
+	}
+
+	return jobs
+}
+
+// runs returns all runs of the jobs of a cronjob, which are running in the period [from, to). The
+// concurrency policy is taken into account, Forbid skips a schedule while a job is still running and
+// Replace stops the running job.
+func (s *CronJobSchedule) runs(from, to time.Time) []jobRun {
+	starts := scheduleTimes(s.schedule, from.Add(-s.runtime), to)
+	runs := make([]jobRun, 0, len(starts))
+
+	for _, start := range starts {
+		if len(runs) > 0 {
+			last := &runs[len(runs)-1]
+
+			switch {
+			case !last.end.After(start):
+			case s.policy == batchV1.ForbidConcurrent:
+				continue
+			case s.policy == batchV1.ReplaceConcurrent:
+				last.end = start
+			}
+		}
+
+		runs = append(runs, jobRun{start: start, end: start.Add(s.runtime)})
+	}
+
+	return runs
+}
+
+// CronJobPeaks simulates the jobs of all cronjobs during a week and returns the highest usage of every
+// resource and when it is first reached. The times are in UTC and start on a monday. Jobs of cronjobs
+// without a known runtime are expected to run all the time, as they could run until their next
+// schedule. Schedules limited to certain days of the month or months (e.g. monthly or yearly
+// schedules) do not run on the same weekdays every week, so their jobs are expected to overlap with
+// the peak of all other jobs as well.
+func CronJobPeaks(usage []*ResourceUsage) []Peak {
+	type event struct {
+		time      time.Time
+		resources v1.ResourceList
+		start     bool
+	}
+
+	var (
+		events   []event
+		current  = v1.ResourceList{}
+		peaks    = map[v1.ResourceName]*Peak{}
+		from, to = simulationStart(), simulationStart().Add(simulationPeriod)
+	)
+
+	for _, u := range usage {
+		if u.CronJob == nil {
+			continue
+		}
+
+		resources := u.CronJob.Resources.DeepCopy()
+
+		// pods are counted, if object counts are enabled
+		if _, ok := u.Resources[v1.ResourcePods]; ok {
+			pods := *resource.NewQuantity(int64(u.CronJob.pods), resource.DecimalSI)
+			resources[v1.ResourcePods] = pods
+			resources[countPrefix+v1.ResourcePods] = pods
+		}
+
+		if u.CronJob.runtime <= 0 || u.CronJob.restricted() {
+			mulResourceList(resources, int64(u.CronJob.concurrentJobs()))
+			addResourceList(current, resources)
+
+			continue
+		}
+
+		runs := u.CronJob.runs(from, to)
+		if len(runs) == 0 {
+			addResourceList(current, resources)
+
+			continue
+		}
+
+		for _, run := range runs {
+			start := run.start
+			if start.Before(from) {
+				start = from
+			}
+
+			events = append(events,
+				event{time: start, resources: resources, start: true},
+				event{time: run.end, resources: resources},
+			)
+		}
+	}
+
+	// at the same time, jobs end before other jobs start
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].time.Equal(events[j].time) {
+			return !events[i].start && events[j].start
+		}
+
+		return events[i].time.Before(events[j].time)
+	})
+
+	updatePeaks := func(t time.Time) {
+		for name, quantity := range current {
+			if peak, ok := peaks[name]; !ok || quantity.Cmp(peak.Quantity) > 0 {
+				peaks[name] = &Peak{Name: name, Quantity: quantity.DeepCopy(), Time: t.UTC()}
+			}
+		}
+	}
+
+	updatePeaks(from)
+
+	for i, e := range events {
+		if e.start {
+			addResourceList(current, e.resources)
+		} else {
+			subResourceList(current, e.resources)
+		}
+
+		// the peak is reached after all events of the same time are processed
+		if i+1 == len(events) || !events[i+1].time.Equal(e.time) {
+			if e.time.Before(to) {
+				updatePeaks(e.time)
+			}
+		}
+	}
+
+	result := make([]Peak, 0, len(peaks))
+
+	for _, peak := range peaks {
+		result = append(result, *peak)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// jobRuntime returns the expected runtime of the jobs of a cronjob. A runtime configured by the user for
+// the cronjob takes precedence over the one for all cronjobs. The activeDeadlineSeconds of the job
+// limits the runtime, as the job controller terminates a job at its deadline.
+func (c *Calculator) jobRuntime(name string, spec *batchV1.JobSpec) time.Duration {
+	var deadline time.Duration
+
+	if spec.ActiveDeadlineSeconds != nil {
+		deadline = time.Duration(*spec.ActiveDeadlineSeconds) * time.Second
+	}
+
+	runtime, ok := c.JobRuntimes[name]
+	if !ok {
+		runtime = c.JobRuntime
+	}
+
+	if runtime <= 0 || (deadline > 0 && deadline < runtime) {
+		return deadline
+	}
+
+	return runtime
+}
+
+// restricted returns true, if the schedule of a cronjob is limited to certain days of the month or
+// months. Unlike daily or weekly schedules, they do not run on the same weekdays every week.
+func (s *CronJobSchedule) restricted() bool {
+	const (
+		allDays   = 1<<32 - 2 // bits 1-31
+		allMonths = 1<<13 - 2 // bits 1-12
+	)
+
+	spec, ok := s.schedule.(*cron.SpecSchedule)
+	if !ok {
+		return false
+	}
+
+	return spec.Dom&allDays != allDays || spec.Month&allMonths != allMonths
+}
+
+// parseSchedule parses the schedule of a cronjob the same way the cronjob controller does. The time
+// zone of the cronjob defaults to the one of the controller manager, which is expected to be UTC.
+func parseSchedule(spec *batchV1.CronJobSpec) (cron.Schedule, error) {
+	schedule := spec.Schedule
+
+	if spec.TimeZone != nil {
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", *spec.TimeZone, schedule)
+	}
+
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schedule %q: %w", schedule, err)
+	}
+
+	return parsed, nil
+}
+
+// scheduleTimes returns all times a schedule triggers in the period [from, to).
+func scheduleTimes(schedule cron.Schedule, from, to time.Time) []time.Time {
+	var times []time.Time
+
+	// Next returns the first time after the given one
+	for next := schedule.Next(from.Add(-time.Second)); !next.IsZero() && next.Before(to); next = schedule.Next(next) {
+		times = append(times, next)
+	}
+
+	return times
+}
+
+// simulationStart returns the start of the period, in which the schedules of cronjobs are simulated.
+// It is a fixed monday, so that the results do not depend on the time kuota-calc is run.
+func simulationStart() time.Time {
+	return time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// concurrencyPolicy returns the concurrency policy of a cronjob, which defaults to Allow.
+func concurrencyPolicy(spec *batchV1.CronJobSpec) batchV1.ConcurrencyPolicy {
+	if spec.ConcurrencyPolicy == "" {
+		return batchV1.AllowConcurrent
 	}
 
-	return &resourceUsage
+	return spec.ConcurrencyPolicy
 }
@@ -2,26 +2,118 @@ package calc
 
 import (
 	"testing"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/require"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestCronJob(t *testing.T) {
 	var tests = []struct {
-		name    string
-		cronjob string
-		cpu     resource.Quantity
-		memory      resource.Quantity
-		replicas    int32
-		maxReplicas int32
-		strategy    string
+		name           string
+		cronjob        string
+		jobRuntime     time.Duration
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+		strategy       string
 	}{
 		{
-			name:    "ok",
-			cronjob: normalCronJob,
-			cpu:     resource.MustParse("1"),
-			memory:  resource.MustParse("4Gi"),
+			name:           "ok",
+			replicas:       1,
+			maxReplicas:    1,
+			cronjob:        normalCronJob,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+			strategy:       "Allow",
+		},
+		{
+			name:           "overlapping jobs",
+			replicas:       2,
+			maxReplicas:    6,
+			cronjob:        overlappingCronJob,
+			cpu:            resource.MustParse("6"),
+			memory:         resource.MustParse("24Gi"),
+			cpuRequests:    resource.MustParse("1500m"),
+			memoryRequests: resource.MustParse("12Gi"),
+			strategy:       "Allow",
+		},
+		{
+			name:           "forbid concurrent jobs",
+			replicas:       2,
+			maxReplicas:    2,
+			cronjob:        forbidCronJob,
+			cpu:            resource.MustParse("2"),
+			memory:         resource.MustParse("8Gi"),
+			cpuRequests:    resource.MustParse("500m"),
+			memoryRequests: resource.MustParse("4Gi"),
+			strategy:       "Forbid",
+		},
+		{
+			name:           "expected runtime",
+			jobRuntime:     5 * time.Minute,
+			replicas:       1,
+			maxReplicas:    5,
+			cronjob:        normalCronJob,
+			cpu:            resource.MustParse("5"),
+			memory:         resource.MustParse("20Gi"),
+			cpuRequests:    resource.MustParse("1250m"),
+			memoryRequests: resource.MustParse("10Gi"),
+			strategy:       "Allow",
+		},
+		{
+			name:           "expected runtime shorter than active deadline",
+			jobRuntime:     15 * time.Minute,
+			replicas:       2,
+			maxReplicas:    4,
+			cronjob:        overlappingCronJob,
+			cpu:            resource.MustParse("4"),
+			memory:         resource.MustParse("16Gi"),
+			cpuRequests:    resource.MustParse("1"),
+			memoryRequests: resource.MustParse("8Gi"),
+			strategy:       "Allow",
+		},
+		{
+			name:           "expected runtime limited by active deadline",
+			jobRuntime:     2 * time.Hour,
+			replicas:       2,
+			maxReplicas:    6,
+			cronjob:        overlappingCronJob,
+			cpu:            resource.MustParse("6"),
+			memory:         resource.MustParse("24Gi"),
+			cpuRequests:    resource.MustParse("1500m"),
+			memoryRequests: resource.MustParse("12Gi"),
+			strategy:       "Allow",
+		},
+		{
+			name:           "overlapping jobs of a monthly schedule",
+			replicas:       1,
+			maxReplicas:    3,
+			cronjob:        monthlyOverlappingCronJob,
+			cpu:            resource.MustParse("3"),
+			memory:         resource.MustParse("12Gi"),
+			cpuRequests:    resource.MustParse("750m"),
+			memoryRequests: resource.MustParse("6Gi"),
+			strategy:       "Allow",
+		},
+		{
+			name:           "time zone",
+			replicas:       1,
+			maxReplicas:    1,
+			cronjob:        timeZoneCronJob,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+			strategy:       "Allow",
 		},
 	}
 
@@ -30,12 +122,17 @@ func TestCronJob(t *testing.T) {
 			test.name, func(t *testing.T) {
 				r := require.New(t)
 
-				usage, err := ResourceQuotaFromYaml([]byte(test.cronjob))
+				calculator := NewCalculator()
+				calculator.JobRuntime = test.jobRuntime
+
+				usage, err := calculator.ResourceQuotaFromYaml([]byte(test.cronjob))
 				r.NoError(err)
 				r.NotEmpty(usage)
 
-				r.Equalf(test.cpu.Value(), usage.CPU.Value(), "cpu value")
-				r.Equalf(test.memory.Value(), usage.Memory.Value(), "memory value")
+				r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+				r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+				r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+				r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
 				r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
 				r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
 				r.Equalf(string(test.strategy), usage.Details.Strategy, "strategy")
@@ -43,3 +140,149 @@ func TestCronJob(t *testing.T) {
 		)
 	}
 }
+
+func TestCronJobInvalidSchedule(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ResourceQuotaFromYaml([]byte(invalidScheduleCronJob))
+	r.Error(err)
+}
+
+func TestCronJobPeaks(t *testing.T) {
+	peakTime := time.Date(2024, time.January, 1, 1, 30, 0, 0, time.UTC)
+
+	var tests = []struct {
+		name     string
+		cronjobs []string
+		peaks    map[v1.ResourceName]string
+		time     time.Time
+	}{
+		{
+			name:     "overlapping nightly jobs",
+			cronjobs: []string{backupCronJob, cleanupCronJob, reportCronJob},
+			peaks: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:    "2",
+				v1.ResourceRequestsMemory: "3Gi",
+			},
+			time: peakTime,
+		},
+		{
+			name:     "cronjob without runtime",
+			cronjobs: []string{backupCronJob, cleanupCronJob, reportCronJob, normalCronJob},
+			peaks: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:    "2250m",
+				v1.ResourceLimitsCPU:      "1",
+				v1.ResourceRequestsMemory: "5Gi",
+				v1.ResourceLimitsMemory:   "4Gi",
+			},
+			time: peakTime,
+		},
+		{
+			name:     "monthly cronjob not scheduled during the week",
+			cronjobs: []string{backupCronJob, cleanupCronJob, reportCronJob, monthlyCronJob},
+			peaks: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:    "6",
+				v1.ResourceRequestsMemory: "5Gi",
+			},
+			time: peakTime,
+		},
+		{
+			// the 3rd of a month is simulated on a wednesday, but can be a monday as well
+			name:     "monthly cronjob scheduled during the week",
+			cronjobs: []string{backupCronJob, cleanupCronJob, reportCronJob, archiveCronJob},
+			peaks: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:    "3",
+				v1.ResourceRequestsMemory: "4Gi",
+			},
+			time: peakTime,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+			calculator.JobRuntimes = map[string]time.Duration{
+				"backup":  2 * time.Hour,
+				"cleanup": 5 * time.Minute,
+				"report":  10 * time.Minute,
+			}
+
+			usage := []*ResourceUsage{}
+
+			for _, cronjob := range test.cronjobs {
+				u, err := calculator.ResourceQuotaFromYaml([]byte(cronjob))
+				r.NoError(err)
+
+				usage = append(usage, u)
+			}
+
+			peaks := CronJobPeaks(usage)
+			r.Len(peaks, len(test.peaks))
+
+			for _, peak := range peaks {
+				expected := resource.MustParse(test.peaks[peak.Name])
+				r.Truef(expected.Equal(peak.Quantity), "%s: expected %s, got %s", peak.Name, expected.String(), peak.Quantity.String())
+
+				if peak.Name == v1.ResourceRequestsCPU {
+					r.Equal(test.time, peak.Time)
+				}
+			}
+		})
+	}
+}
+
+func TestCronJobRuns(t *testing.T) {
+	schedule, err := cron.ParseStandard("*/10 * * * *")
+	require.NoError(t, err)
+
+	from := simulationStart()
+	to := from.Add(time.Hour)
+
+	var tests = []struct {
+		name   string
+		policy batchV1.ConcurrencyPolicy
+		starts []int
+		ends   []int
+	}{
+		{
+			name:   "allow",
+			policy: batchV1.AllowConcurrent,
+			starts: []int{-20, -10, 0, 10, 20, 30, 40, 50},
+			ends:   []int{5, 15, 25, 35, 45, 55, 65, 75},
+		},
+		{
+			name:   "forbid",
+			policy: batchV1.ForbidConcurrent,
+			starts: []int{-20, 10, 40},
+			ends:   []int{5, 35, 65},
+		},
+		{
+			name:   "replace",
+			policy: batchV1.ReplaceConcurrent,
+			starts: []int{-20, -10, 0, 10, 20, 30, 40, 50},
+			ends:   []int{-10, 0, 10, 20, 30, 40, 50, 75},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			s := CronJobSchedule{
+				schedule: schedule,
+				policy:   test.policy,
+				runtime:  25 * time.Minute,
+			}
+
+			runs := s.runs(from, to)
+			r.Len(runs, len(test.starts))
+
+			for i, run := range runs {
+				r.Equal(from.Add(time.Duration(test.starts[i])*time.Minute), run.start, "start of run %d", i)
+				r.Equal(from.Add(time.Duration(test.ends[i])*time.Minute), run.end, "end of run %d", i)
+			}
+		})
+	}
+}
@@ -1,24 +1,53 @@
 package calc
 
 import (
+	"fmt"
+
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func daemonSet(dSet appsv1.DaemonSet) *ResourceUsage {
-	cpu, memory := podResources(&dSet.Spec.Template.Spec)
+// calculates the resources a single daemonset needs. A pod runs on every matching node and during a
+// rolling update, maxSurge nodes run an additional pod.
+func (c *Calculator) daemonSet(dSet appsv1.DaemonSet) (*ResourceUsage, error) {
+	var (
+		surge int
+	)
+
+	replicas := c.daemonSetNodes(&dSet.Spec.Template.Spec)
+	strategy := dSet.Spec.UpdateStrategy
+
+	// https://github.com/kubernetes/api/blob/v0.29.15/apps/v1/types.go#L617
+	if strategy.Type == "" {
+		strategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+	}
+
+	if strategy.Type == appsv1.RollingUpdateDaemonSetStrategyType && strategy.RollingUpdate != nil && strategy.RollingUpdate.MaxSurge != nil {
+		var err error
+
+		// docs say, absolute number is calculated by rounding up.
+		surge, err = intstr.GetScaledValueFromIntOrPercent(strategy.RollingUpdate.MaxSurge, int(replicas), true)
+		if err != nil {
+			return nil, fmt.Errorf("daemonset: %s: %w", dSet.Name, err)
+		}
+	}
+
+	maxReplicas := replicas + int32(surge)
+
+	resources := c.podResources(&dSet.Spec.Template.Spec)
+	mulResourceList(resources, int64(maxReplicas))
 
 	resourceUsage := ResourceUsage{
-		CPU:    cpu,
-		Memory: memory,
+		Resources: resources,
 		Details: Details{
 			Version:     dSet.APIVersion,
 			Kind:        dSet.Kind,
 			Name:        dSet.Name,
-			Strategy:    "",
-			Replicas:    1,
-			MaxReplicas: 1,
+			Strategy:    string(strategy.Type),
+			Replicas:    replicas,
+			MaxReplicas: maxReplicas,
 		},
 	}
 
-	return &resourceUsage
+	return &resourceUsage, nil
 }
@@ -1,30 +1,112 @@
 package calc
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestDaemonSet(t *testing.T) {
 	var tests = []struct {
-		name      string
-		daemonset string
-		cpu       resource.Quantity
-		memory      resource.Quantity
-		replicas    int32
-		maxReplicas int32
-		strategy    appsv1.StatefulSetUpdateStrategyType
+		name           string
+		daemonset      string
+		nodeCount      int32
+		nodes          []string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+		strategy       appsv1.DaemonSetUpdateStrategyType
 	}{
 		{
-			name:      "ok",
-			daemonset: normalDaemonSet,
-			replicas: 1,
-			maxReplicas: 1,
-			cpu:       resource.MustParse("2"),
-			memory:    resource.MustParse("2Gi"),
+			name:           "ok",
+			daemonset:      normalDaemonSet,
+			replicas:       1,
+			maxReplicas:    1,
+			cpu:            resource.MustParse("2"),
+			memory:         resource.MustParse("2Gi"),
+			cpuRequests:    resource.MustParse("500m"),
+			memoryRequests: resource.MustParse("200Mi"),
+			strategy:       appsv1.RollingUpdateDaemonSetStrategyType,
+		},
+		{
+			name:           "node count",
+			daemonset:      normalDaemonSet,
+			nodeCount:      40,
+			replicas:       40,
+			maxReplicas:    40,
+			cpu:            resource.MustParse("80"),
+			memory:         resource.MustParse("80Gi"),
+			cpuRequests:    resource.MustParse("20"),
+			memoryRequests: resource.MustParse("8000Mi"),
+			strategy:       appsv1.RollingUpdateDaemonSetStrategyType,
+		},
+		{
+			name:           "max surge",
+			daemonset:      selectorDaemonSet,
+			nodeCount:      40,
+			replicas:       40,
+			maxReplicas:    50,
+			cpu:            resource.MustParse("0"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("5"),
+			memoryRequests: resource.MustParse("5000Mi"),
+			strategy:       appsv1.RollingUpdateDaemonSetStrategyType,
+		},
+		{
+			name:           "on delete",
+			daemonset:      onDeleteDaemonSet,
+			nodeCount:      3,
+			replicas:       3,
+			maxReplicas:    3,
+			cpu:            resource.MustParse("0"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("300m"),
+			memoryRequests: resource.MustParse("300Mi"),
+			strategy:       appsv1.OnDeleteDaemonSetStrategyType,
+		},
+		{
+			name:           "nodes without taints",
+			daemonset:      normalDaemonSet,
+			nodes:          []string{workerNodeA, workerNodeB, gpuNode, controlPlaneNode, preferNoScheduleNode, notReadyNode},
+			nodeCount:      40,
+			replicas:       4,
+			maxReplicas:    4,
+			cpu:            resource.MustParse("8"),
+			memory:         resource.MustParse("8Gi"),
+			cpuRequests:    resource.MustParse("2"),
+			memoryRequests: resource.MustParse("800Mi"),
+			strategy:       appsv1.RollingUpdateDaemonSetStrategyType,
+		},
+		{
+			name:           "node selector and tolerations",
+			daemonset:      selectorDaemonSet,
+			nodes:          []string{workerNodeA, workerNodeB, gpuNode, controlPlaneNode, preferNoScheduleNode, notReadyNode},
+			replicas:       5,
+			maxReplicas:    7,
+			cpu:            resource.MustParse("0"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("700m"),
+			memoryRequests: resource.MustParse("700Mi"),
+			strategy:       appsv1.RollingUpdateDaemonSetStrategyType,
+		},
+		{
+			name:           "node affinity",
+			daemonset:      affinityDaemonSet,
+			nodes:          []string{workerNodeA, workerNodeB, gpuNode, controlPlaneNode, preferNoScheduleNode, notReadyNode},
+			replicas:       1,
+			maxReplicas:    1,
+			cpu:            resource.MustParse("0"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("100m"),
+			memoryRequests: resource.MustParse("100Mi"),
+			strategy:       appsv1.RollingUpdateDaemonSetStrategyType,
 		},
 	}
 
@@ -33,12 +115,21 @@ func TestDaemonSet(t *testing.T) {
 			test.name, func(t *testing.T) {
 				r := require.New(t)
 
-				usage, err := ResourceQuotaFromYaml([]byte(test.daemonset))
+				calculator := NewCalculator()
+				calculator.NodeCount = test.nodeCount
+
+				for _, node := range test.nodes {
+					r.NoError(calculator.AddNode([]byte(node)))
+				}
+
+				usage, err := calculator.ResourceQuotaFromYaml([]byte(test.daemonset))
 				r.NoError(err)
 				r.NotEmpty(usage)
 
-				r.Equalf(test.cpu.Value(), usage.CPU.Value(), "cpu value")
-				r.Equalf(test.memory.Value(), usage.Memory.Value(), "memory value")
+				r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+				r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+				r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+				r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
 				r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
 				r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
 				r.Equalf(string(test.strategy), usage.Details.Strategy, "strategy")
@@ -46,3 +137,16 @@ func TestDaemonSet(t *testing.T) {
 		)
 	}
 }
+
+func TestAddNode(t *testing.T) {
+	r := require.New(t)
+
+	calculator := NewCalculator()
+
+	err := calculator.AddNode([]byte(normalPod))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+
+	r.NoError(calculator.AddNode([]byte(nodeList)))
+	r.Len(calculator.nodes, 2)
+}
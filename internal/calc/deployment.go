@@ -2,113 +2,107 @@ package calc
 
 import (
 	"fmt"
-	"math"
 
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// calculates the cpu/memory resources a single deployment needs. Replicas and the deployment
-// strategy are taken into account.
-func deployment(deployment appsv1.Deployment) (*ResourceUsage, error) {
+const (
+	// defaultMaxSurge and defaultMaxUnavailable are the defaults of the rolling update of a deployment
+	// (https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment).
+	defaultMaxSurge       = "25%"
+	defaultMaxUnavailable = "25%"
+)
+
+// calculates the resources a single deployment needs. Replicas and the deployment strategy are taken
+// into account.
+func (c *Calculator) deployment(deployment appsv1.Deployment) (*ResourceUsage, error) {
 	var (
-		resourceOverhead float64 // max overhead compute resources (percent)
-		podOverhead      int32   // max overhead pods during deployment
+		podOverhead int32 // max overhead pods during deployment
+		replicas    int32 = 1
 	)
 
-	replicas := deployment.Spec.Replicas
-	strategy := deployment.Spec.Strategy
-
-	if *replicas == 0 {
-		return &ResourceUsage{
-			CPU:    new(resource.Quantity),
-			Memory: new(resource.Quantity),
-			Details: Details{
-				Version:     deployment.APIVersion,
-				Kind:        deployment.Kind,
-				Name:        deployment.Name,
-				Replicas:    *replicas,
-				MaxReplicas: *replicas,
-				Strategy:    string(strategy.Type),
-			},
-		}, nil
+	// https://github.com/kubernetes/api/blob/v0.29.15/apps/v1/types.go#L367
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
 	}
 
+	// an autoscaled deployment runs up to the maximum replicas of its autoscaler, a rolling update can
+	// start at any time
+	scaled, autoscalerMax := c.scaledReplicas(deployment.Kind, deployment.Name, replicas)
+
+	strategy := deployment.Spec.Strategy
+
 	switch strategy.Type {
 	case appsv1.RecreateDeploymentStrategyType:
 		// no overhead on recreate
-		resourceOverhead = 1
 		podOverhead = 0
-	case "":
-		// RollingUpdate is the default an can be an empty string. If so, set the defaults
-		// (https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment) and continue calculation.
-		defaults := intstr.FromString("25%")
-		strategy = appsv1.DeploymentStrategy{
-			Type: appsv1.RollingUpdateDeploymentStrategyType,
-			RollingUpdate: &appsv1.RollingUpdateDeployment{
-				MaxUnavailable: &defaults,
-				MaxSurge:       &defaults,
-			},
-		}
-
-		fallthrough
-	case appsv1.RollingUpdateDeploymentStrategyType:
-		// Documentation: https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment
-		// all default values are set as stated in the docs
-		var (
-			maxUnavailableValue intstr.IntOrString
-			maxSurgeValue       intstr.IntOrString
-		)
-
-		// can be nil, if so apply default value
-		if strategy.RollingUpdate == nil {
-			maxUnavailableValue = intstr.FromString("25%")
-			maxSurgeValue = intstr.FromString("25%")
-		} else {
-			maxUnavailableValue = *strategy.RollingUpdate.MaxUnavailable
-			maxSurgeValue = *strategy.RollingUpdate.MaxSurge
-		}
+	case "", appsv1.RollingUpdateDeploymentStrategyType:
+		// RollingUpdate is the default an can be an empty string.
+		strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
 
-		// docs say, that the asolute number is calculated by rounding down.
-		maxUnavailable, err := intstr.GetValueFromIntOrPercent(&maxUnavailableValue, int(*replicas), false)
+		maxSurge, _, err := rollingUpdateValues(strategy.RollingUpdate, scaled)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("deployment: %s: %w", deployment.Name, err)
 		}
 
-		// docs say, absolute number is calculated by rounding up.
-		maxSurge, err := intstr.GetValueFromIntOrPercent(&maxSurgeValue, int(*replicas), true)
-		if err != nil {
-			return nil, err
-		}
-
-		// podOverhead is the number of pods which can run more during a deployment
-		podOverhead = int32(maxSurge - maxUnavailable)
-
-		resourceOverhead = (float64(podOverhead) / float64(*replicas)) + 1
+		// podOverhead is the number of pods which can run more during a deployment. maxUnavailable does
+		// not lower the peak, as the new pods can be created before any old pod is terminated.
+		podOverhead = int32(maxSurge)
 	default:
+		// the api server rejects an unknown strategy, so the calculation stops instead of guessing
 		return nil, fmt.Errorf("deployment: %s deployment strategy %q is unknown", deployment.Name, strategy.Type)
 	}
 
-	cpu, memory := podResources(&deployment.Spec.Template.Spec)
-
-	mem := float64(memory.Value()) * float64(*replicas) * resourceOverhead
-	memory.Set(int64(math.Round(mem)))
+	// a deployment scaled to zero does not run any pods, not even during a rollout
+	if scaled == 0 {
+		podOverhead = 0
+	}
 
-	cpu.SetMilli(int64(math.Round(float64(cpu.MilliValue()) * float64(*replicas) * resourceOverhead)))
+	resources := c.podResources(&deployment.Spec.Template.Spec)
+	mulResourceList(resources, int64(scaled+podOverhead))
 
 	resourceUsage := ResourceUsage{
-		CPU:    cpu,
-		Memory: memory,
+		Resources: resources,
 		Details: Details{
-			Version:     deployment.APIVersion,
-			Kind:        deployment.Kind,
-			Name:        deployment.Name,
-			Replicas:    *replicas,
-			Strategy:    string(strategy.Type),
-			MaxReplicas: *replicas + podOverhead,
+			Version:               deployment.APIVersion,
+			Kind:                  deployment.Kind,
+			Name:                  deployment.Name,
+			Replicas:              replicas,
+			Strategy:              string(strategy.Type),
+			MaxReplicas:           scaled + podOverhead,
+			AutoscalerMaxReplicas: autoscalerMax,
 		},
 	}
 
 	return &resourceUsage, nil
 }
+
+// rollingUpdateValues returns the absolute maxSurge and maxUnavailable of a rolling update. Both of
+// them default to 25%, if they are not set.
+func rollingUpdateValues(rollingUpdate *appsv1.RollingUpdateDeployment, replicas int32) (maxSurge, maxUnavailable int, err error) {
+	maxSurgeValue := intstr.FromString(defaultMaxSurge)
+	maxUnavailableValue := intstr.FromString(defaultMaxUnavailable)
+
+	if rollingUpdate != nil && rollingUpdate.MaxSurge != nil {
+		maxSurgeValue = *rollingUpdate.MaxSurge
+	}
+
+	if rollingUpdate != nil && rollingUpdate.MaxUnavailable != nil {
+		maxUnavailableValue = *rollingUpdate.MaxUnavailable
+	}
+
+	// docs say, absolute number is calculated by rounding up.
+	maxSurge, err = intstr.GetScaledValueFromIntOrPercent(&maxSurgeValue, int(replicas), true)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// docs say, that the asolute number is calculated by rounding down.
+	maxUnavailable, err = intstr.GetScaledValueFromIntOrPercent(&maxUnavailableValue, int(replicas), false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return maxSurge, maxUnavailable, nil
+}
@@ -5,81 +5,99 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestDeployment(t *testing.T) {
 	var tests = []struct {
-		name        string
-		deployment  string
-		cpu         resource.Quantity
-		memory      resource.Quantity
-		replicas    int32
-		maxReplicas int32
-		strategy    appsv1.DeploymentStrategyType
+		name           string
+		deployment     string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+		strategy       appsv1.DeploymentStrategyType
 	}{
 		{
-			name:        "normal deployment",
-			deployment:  normalDeployment,
-			cpu:         resource.MustParse("5500m"),
-			memory:      resource.MustParse("44Gi"),
-			replicas:    10,
-			maxReplicas: 11,
-			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
-		},
-		{
-			name:        "deployment without strategy",
-			deployment:  deploymentWithoutStrategy,
-			cpu:         resource.MustParse("11"),
-			memory:      resource.MustParse("44Gi"),
-			replicas:    10,
-			maxReplicas: 11,
-			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
-		},
-		{
-			name:        "deployment with absolute unavailable/surge values",
-			deployment:  deploymentWithAbsoluteValues,
-			cpu:         resource.MustParse("12"),
-			memory:      resource.MustParse("48Gi"),
-			replicas:    10,
-			maxReplicas: 12,
-			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
-		},
-		{
-			name:        "zero replica deployment",
-			deployment:  zeroReplicaDeployment,
-			cpu:         resource.MustParse("0"),
-			memory:      resource.MustParse("0"),
-			replicas:    0,
-			maxReplicas: 0,
-			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+			name:           "normal deployment",
+			deployment:     normalDeployment,
+			cpu:            resource.MustParse("6500m"),
+			memory:         resource.MustParse("52Gi"),
+			cpuRequests:    resource.MustParse("3250m"),
+			memoryRequests: resource.MustParse("26Gi"),
+			replicas:       10,
+			maxReplicas:    13,
+			strategy:       appsv1.RollingUpdateDeploymentStrategyType,
 		},
 		{
-			name:        "recreate deployment",
-			deployment:  recrateDeployment,
-			cpu:         resource.MustParse("10"),
-			memory:      resource.MustParse("40Gi"),
-			replicas:    10,
-			maxReplicas: 10,
-			strategy:    appsv1.RecreateDeploymentStrategyType,
+			name:           "deployment without strategy",
+			deployment:     deploymentWithoutStrategy,
+			cpu:            resource.MustParse("13"),
+			memory:         resource.MustParse("52Gi"),
+			cpuRequests:    resource.MustParse("3250m"),
+			memoryRequests: resource.MustParse("26Gi"),
+			replicas:       10,
+			maxReplicas:    13,
+			strategy:       appsv1.RollingUpdateDeploymentStrategyType,
 		},
 		{
-			name:        "deployment without max unavailable/surge values",
-			deployment:  deploymentWithoutValues,
-			cpu:         resource.MustParse("11"),
-			memory:      resource.MustParse("44Gi"),
-			replicas:    10,
-			maxReplicas: 11,
-			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+			name:           "deployment with absolute unavailable/surge values",
+			deployment:     deploymentWithAbsoluteValues,
+			cpu:            resource.MustParse("12"),
+			memory:         resource.MustParse("48Gi"),
+			cpuRequests:    resource.MustParse("3"),
+			memoryRequests: resource.MustParse("24Gi"),
+			replicas:       10,
+			maxReplicas:    12,
+			strategy:       appsv1.RollingUpdateDeploymentStrategyType,
 		},
 		{
-			name:        "deployment with init container(s)",
-			deployment:  initContainerDeployment,
-			cpu:         resource.MustParse("4400m"),
-			memory:      resource.MustParse("17184Mi"),
-			replicas:    3,
-			maxReplicas: 4,
-			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+			name:           "zero replica deployment",
+			deployment:     zeroReplicaDeployment,
+			cpu:            resource.MustParse("0"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("0"),
+			memoryRequests: resource.MustParse("0"),
+			replicas:       0,
+			maxReplicas:    0,
+			strategy:       appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:           "recreate deployment",
+			deployment:     recrateDeployment,
+			cpu:            resource.MustParse("10"),
+			memory:         resource.MustParse("40Gi"),
+			cpuRequests:    resource.MustParse("2500m"),
+			memoryRequests: resource.MustParse("20Gi"),
+			replicas:       10,
+			maxReplicas:    10,
+			strategy:       appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:           "deployment without max unavailable/surge values",
+			deployment:     deploymentWithoutValues,
+			cpu:            resource.MustParse("13"),
+			memory:         resource.MustParse("52Gi"),
+			cpuRequests:    resource.MustParse("3250m"),
+			memoryRequests: resource.MustParse("26Gi"),
+			replicas:       10,
+			maxReplicas:    13,
+			strategy:       appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:           "deployment with init container(s)",
+			deployment:     initContainerDeployment,
+			cpu:            resource.MustParse("4"),
+			memory:         resource.MustParse("16Gi"),
+			cpuRequests:    resource.MustParse("1"),
+			memoryRequests: resource.MustParse("8Gi"),
+			replicas:       3,
+			maxReplicas:    4,
+			strategy:       appsv1.RollingUpdateDeploymentStrategyType,
 		},
 	}
 
@@ -91,11 +109,240 @@ func TestDeployment(t *testing.T) {
 			r.NoError(err)
 			r.NotEmpty(usage)
 
-			r.Equalf(test.cpu.MilliValue(), usage.CPU.MilliValue(), "cpu value")
-			r.Equal(0, test.memory.Cmp(*usage.Memory), "memory value %d != %d", test.memory.Value(), usage.Memory.Value())
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equal(0, test.memory.Cmp(*quantity(usage, v1.ResourceLimitsMemory)), "memory value %d != %d",
+				test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value())
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equal(0, test.memoryRequests.Cmp(*quantity(usage, v1.ResourceRequestsMemory)), "memory requests value %d != %d",
+				test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value())
 			r.Equal(test.replicas, usage.Details.Replicas, "replicas")
 			r.Equal(string(test.strategy), usage.Details.Strategy, "strategy")
 			r.Equal(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
 		})
 	}
 }
+
+func TestDeploymentEphemeralStorage(t *testing.T) {
+	var tests = []struct {
+		name            string
+		emptyDirs       bool
+		storage         resource.Quantity
+		storageRequests resource.Quantity
+	}{
+		{
+			name:            "without emptyDir volumes",
+			storage:         resource.MustParse("10Gi"),
+			storageRequests: resource.MustParse("5Gi"),
+		},
+		{
+			name:            "with emptyDir volumes",
+			emptyDirs:       true,
+			storage:         resource.MustParse("12800Mi"),
+			storageRequests: resource.MustParse("7680Mi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+			calculator.EmptyDirs = test.emptyDirs
+
+			usage, err := calculator.ResourceQuotaFromYaml([]byte(ephemeralStorageDeployment))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.storage.Value(), quantity(usage, v1.ResourceLimitsEphemeralStorage).Value(), "ephemeral-storage value")
+			r.Equalf(test.storageRequests.Value(), quantity(usage, v1.ResourceRequestsEphemeralStorage).Value(), "ephemeral-storage requests value")
+			r.Equalf(int32(5), usage.Details.MaxReplicas, "maxReplicas")
+		})
+	}
+}
+
+func TestDeploymentDefaults(t *testing.T) {
+	intOrString := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+
+	int32Ptr := func(value int32) *int32 {
+		return &value
+	}
+
+	var tests = []struct {
+		name        string
+		replicas    *int32
+		strategy    appsv1.DeploymentStrategy
+		maxReplicas int32
+		expected    int32
+	}{
+		{
+			name:        "no replicas and no strategy",
+			strategy:    appsv1.DeploymentStrategy{},
+			expected:    1,
+			maxReplicas: 2,
+		},
+		{
+			name:        "no replicas and recreate",
+			strategy:    appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			expected:    1,
+			maxReplicas: 1,
+		},
+		{
+			name: "no replicas and max surge only",
+			strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: intOrString(intstr.FromInt32(3))},
+			},
+			expected:    1,
+			maxReplicas: 4,
+		},
+		{
+			name:        "rolling update without parameters",
+			replicas:    int32Ptr(10),
+			strategy:    appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType},
+			expected:    10,
+			maxReplicas: 13,
+		},
+		{
+			name:     "rolling update with empty parameters",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{},
+			},
+			expected:    10,
+			maxReplicas: 13,
+		},
+		{
+			name:     "max surge only",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: intOrString(intstr.FromInt32(5))},
+			},
+			expected:    10,
+			maxReplicas: 15,
+		},
+		{
+			name:     "max unavailable only",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: intOrString(intstr.FromInt32(1))},
+			},
+			expected:    10,
+			maxReplicas: 13,
+		},
+		{
+			name:     "max unavailable larger than max surge",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxSurge:       intOrString(intstr.FromInt32(3)),
+					MaxUnavailable: intOrString(intstr.FromInt32(5)),
+				},
+			},
+			expected:    10,
+			maxReplicas: 13,
+		},
+		{
+			name:     "max unavailable without max surge",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxSurge:       intOrString(intstr.FromInt32(0)),
+					MaxUnavailable: intOrString(intstr.FromInt32(1)),
+				},
+			},
+			expected:    10,
+			maxReplicas: 10,
+		},
+		{
+			name:     "max surge and max unavailable",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxSurge:       intOrString(intstr.FromString("50%")),
+					MaxUnavailable: intOrString(intstr.FromInt32(1)),
+				},
+			},
+			expected:    10,
+			maxReplicas: 15,
+		},
+		{
+			name:     "no strategy type with max surge only",
+			replicas: int32Ptr(10),
+			strategy: appsv1.DeploymentStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: intOrString(intstr.FromString("100%"))},
+			},
+			expected:    10,
+			maxReplicas: 20,
+		},
+		{
+			name:     "zero replicas",
+			replicas: int32Ptr(0),
+			strategy: appsv1.DeploymentStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: intOrString(intstr.FromInt32(3))},
+			},
+			expected:    0,
+			maxReplicas: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			deployment := appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: test.replicas,
+					Strategy: test.strategy,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{container("app", "100m", "128Mi")},
+						},
+					},
+				},
+			}
+
+			usage, err := NewCalculator().deployment(deployment)
+			r.NoError(err)
+
+			r.Equalf(test.expected, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+			r.Equalf(int64(test.maxReplicas)*100, quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+
+			if test.strategy.Type == appsv1.RecreateDeploymentStrategyType {
+				r.Equalf(string(appsv1.RecreateDeploymentStrategyType), usage.Details.Strategy, "strategy")
+			} else {
+				r.Equalf(string(appsv1.RollingUpdateDeploymentStrategyType), usage.Details.Strategy, "strategy")
+			}
+		})
+	}
+}
+
+func TestDeploymentInvalidStrategy(t *testing.T) {
+	r := require.New(t)
+
+	invalid := intstr.FromString("many")
+
+	_, err := NewCalculator().deployment(appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &invalid},
+			},
+		},
+	})
+	r.Error(err)
+
+	_, err = NewCalculator().deployment(appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: "BlueGreen"},
+		},
+	})
+	r.Error(err)
+}
@@ -2,21 +2,54 @@ package calc
 
 import batchV1 "k8s.io/api/batch/v1"
 
-func job(job batchV1.Job) *ResourceUsage {
-	cpu, memory := podResources(&job.Spec.Template.Spec)
+// calculates the resources a single job needs. A job runs up to parallelism pods at once, but never
+// more than the number of completions.
+func (c *Calculator) job(job batchV1.Job) *ResourceUsage {
+	parallelism, maxPods := jobPods(&job.Spec)
+
+	resources := c.podResources(&job.Spec.Template.Spec)
+	mulResourceList(resources, int64(maxPods))
 
 	resourceUsage := ResourceUsage{
-		CPU:    cpu,
-		Memory: memory,
+		Resources: resources,
 		Details: Details{
 			Version:     job.APIVersion,
 			Kind:        job.Kind,
 			Name:        job.Name,
-			Strategy:    "",
-			Replicas:    0,
-			MaxReplicas: 0,
+			Strategy:    string(completionMode(&job.Spec)),
+			Replicas:    parallelism,
+			MaxReplicas: maxPods,
 		},
 	}
 
 	return &resourceUsage
 }
+
+// jobPods returns the parallelism of a job and the peak number of pods running at once, which is
+// min(parallelism, completions). Without completions (work queue), all parallel pods can run at once.
+// Indexed jobs start at most one pod per index, which is bounded by the completions as well.
+func jobPods(spec *batchV1.JobSpec) (parallelism, maxPods int32) {
+	// https://github.com/kubernetes/api/blob/v0.29.15/batch/v1/types.go#L283
+	parallelism = 1
+	if spec.Parallelism != nil {
+		parallelism = *spec.Parallelism
+	}
+
+	maxPods = parallelism
+
+	// indexed jobs must have completions, the api server rejects them otherwise
+	if spec.Completions != nil && *spec.Completions < maxPods {
+		maxPods = *spec.Completions
+	}
+
+	return parallelism, maxPods
+}
+
+// completionMode returns the completion mode of a job, which defaults to NonIndexed.
+func completionMode(spec *batchV1.JobSpec) batchV1.CompletionMode {
+	if spec.CompletionMode == nil {
+		return batchV1.NonIndexedCompletion
+	}
+
+	return *spec.CompletionMode
+}
@@ -4,24 +4,65 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestJob(t *testing.T) {
 	var tests = []struct {
-		name        string
-		job         string
-		cpu         resource.Quantity
-		memory      resource.Quantity
-		replicas    int32
-		maxReplicas int32
-		strategy    string
+		name           string
+		job            string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+		strategy       string
 	}{
 		{
-			name:   "ok",
-			job:    normalJob,
-			cpu:    resource.MustParse("1"),
-			memory: resource.MustParse("4Gi"),
+			name:           "ok",
+			replicas:       1,
+			maxReplicas:    1,
+			job:            normalJob,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+			strategy:       "NonIndexed",
+		},
+		{
+			name:           "parallelism bounded by completions",
+			replicas:       10,
+			maxReplicas:    4,
+			job:            parallelJob,
+			cpu:            resource.MustParse("4"),
+			memory:         resource.MustParse("16Gi"),
+			cpuRequests:    resource.MustParse("1"),
+			memoryRequests: resource.MustParse("8Gi"),
+			strategy:       "NonIndexed",
+		},
+		{
+			name:           "work queue",
+			replicas:       5,
+			maxReplicas:    5,
+			job:            workQueueJob,
+			cpu:            resource.MustParse("5"),
+			memory:         resource.MustParse("20Gi"),
+			cpuRequests:    resource.MustParse("1250m"),
+			memoryRequests: resource.MustParse("10Gi"),
+			strategy:       "NonIndexed",
+		},
+		{
+			name:           "indexed",
+			replicas:       10,
+			maxReplicas:    3,
+			job:            indexedJob,
+			cpu:            resource.MustParse("3"),
+			memory:         resource.MustParse("12Gi"),
+			cpuRequests:    resource.MustParse("750m"),
+			memoryRequests: resource.MustParse("6Gi"),
+			strategy:       "Indexed",
 		},
 	}
 
@@ -34,8 +75,10 @@ func TestJob(t *testing.T) {
 				r.NoError(err)
 				r.NotEmpty(usage)
 
-				r.Equalf(test.cpu.Value(), usage.CPU.Value(), "cpu value")
-				r.Equalf(test.memory.Value(), usage.Memory.Value(), "memory value")
+				r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+				r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+				r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+				r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
 				r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
 				r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
 				r.Equalf(string(test.strategy), usage.Details.Strategy, "strategy")
@@ -0,0 +1,197 @@
+package calc
+
+import (
+	"fmt"
+
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	kedaAPIVersion = "keda.sh/v1alpha1"
+
+	// https://keda.sh/docs/2.13/reference/scaledobject-spec/#minreplicacount-maxreplicacount
+	defaultMaxReplicaCount = 100
+	defaultScaleTargetKind = "Deployment"
+	// https://keda.sh/docs/2.13/reference/scaledjob-spec/#scalingstrategy
+	defaultScalingStrategy = "default"
+)
+
+// scaledObject contains the fields of a KEDA ScaledObject, which are needed to calculate the replicas
+// of its scale target. KEDA is not part of the client-go scheme, so only the needed fields are
+// decoded instead of depending on the KEDA api module.
+type scaledObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              scaledObjectSpec `json:"spec"`
+}
+
+type scaledObjectSpec struct {
+	ScaleTargetRef  scaleTarget `json:"scaleTargetRef"`
+	MaxReplicaCount *int32      `json:"maxReplicaCount,omitempty"`
+}
+
+type scaleTarget struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name"`
+}
+
+// scaledJob contains the fields of a KEDA ScaledJob, which are needed to calculate its resources.
+type scaledJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              scaledJobSpec `json:"spec"`
+}
+
+type scaledJobSpec struct {
+	JobTargetRef    *batchV1.JobSpec `json:"jobTargetRef,omitempty"`
+	MaxReplicaCount *int32           `json:"maxReplicaCount,omitempty"`
+	ScalingStrategy scalingStrategy  `json:"scalingStrategy,omitempty"`
+}
+
+type scalingStrategy struct {
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// DeepCopyObject implements the runtime.Object interface.
+func (in *scaledObject) DeepCopyObject() runtime.Object {
+	out := &scaledObject{
+		TypeMeta: in.TypeMeta,
+		Spec: scaledObjectSpec{
+			ScaleTargetRef: in.Spec.ScaleTargetRef,
+		},
+	}
+
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	if in.Spec.MaxReplicaCount != nil {
+		maxReplicaCount := *in.Spec.MaxReplicaCount
+		out.Spec.MaxReplicaCount = &maxReplicaCount
+	}
+
+	return out
+}
+
+// DeepCopyObject implements the runtime.Object interface.
+func (in *scaledJob) DeepCopyObject() runtime.Object {
+	out := &scaledJob{
+		TypeMeta: in.TypeMeta,
+		Spec: scaledJobSpec{
+			ScalingStrategy: in.Spec.ScalingStrategy,
+		},
+	}
+
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	if in.Spec.JobTargetRef != nil {
+		out.Spec.JobTargetRef = in.Spec.JobTargetRef.DeepCopy()
+	}
+
+	if in.Spec.MaxReplicaCount != nil {
+		maxReplicaCount := *in.Spec.MaxReplicaCount
+		out.Spec.MaxReplicaCount = &maxReplicaCount
+	}
+
+	return out
+}
+
+// decodeKEDA decodes a single yaml document containing a KEDA resource. The returned object is nil, if
+// the document does not contain a supported KEDA resource.
+// Currently supported:
+// * keda.sh/v1alpha1 - ScaledObject
+// * keda.sh/v1alpha1 - ScaledJob
+func decodeKEDA(yamlData []byte) (runtime.Object, *schema.GroupVersionKind, error) {
+	var (
+		object   runtime.Object
+		typeMeta metav1.TypeMeta
+	)
+
+	if err := yaml.Unmarshal(yamlData, &typeMeta); err != nil {
+		return nil, nil, fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	gvk := typeMeta.GroupVersionKind()
+
+	if typeMeta.APIVersion != kedaAPIVersion {
+		return nil, &gvk, nil
+	}
+
+	switch gvk.Kind {
+	case "ScaledObject":
+		object = &scaledObject{}
+	case "ScaledJob":
+		object = &scaledJob{}
+	default:
+		return nil, &gvk, nil
+	}
+
+	if err := yaml.Unmarshal(yamlData, object); err != nil {
+		return nil, nil, fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	return object, &gvk, nil
+}
+
+// calculates the resources a single KEDA ScaledJob needs. A ScaledJob creates up to maxReplicaCount
+// jobs from its job template, which all run at the same time. Every job runs up to parallelism
+// pods at once, but never more than the number of completions.
+func (c *Calculator) scaledJob(s scaledJob) *ResourceUsage {
+	var (
+		maxPods   int32
+		resources = v1.ResourceList{}
+	)
+
+	jobs := maxReplicaCount(s.Spec.MaxReplicaCount)
+
+	if s.Spec.JobTargetRef != nil {
+		_, maxPods = jobPods(s.Spec.JobTargetRef)
+
+		resources = c.podResources(&s.Spec.JobTargetRef.Template.Spec)
+		mulResourceList(resources, int64(jobs)*int64(maxPods))
+	}
+
+	strategy := s.Spec.ScalingStrategy.Strategy
+	if strategy == "" {
+		strategy = defaultScalingStrategy
+	}
+
+	resourceUsage := ResourceUsage{
+		Resources: resources,
+		Details: Details{
+			Version:               s.APIVersion,
+			Kind:                  s.Kind,
+			Name:                  s.Name,
+			Strategy:              strategy,
+			Replicas:              maxPods,
+			MaxReplicas:           jobs * maxPods,
+			AutoscalerMaxReplicas: jobs,
+		},
+	}
+
+	return &resourceUsage
+}
+
+// scaleTargetKind returns the kind of the scale target of a ScaledObject, which defaults to
+// Deployment.
+func (s *scaledObject) scaleTargetKind() string {
+	if s.Spec.ScaleTargetRef.Kind == "" {
+		return defaultScaleTargetKind
+	}
+
+	return s.Spec.ScaleTargetRef.Kind
+}
+
+// maxReplicaCount returns the maximum replicas of a ScaledObject or the maximum jobs of a ScaledJob,
+// which default to 100.
+func maxReplicaCount(count *int32) int32 {
+	if count == nil {
+		return defaultMaxReplicaCount
+	}
+
+	return *count
+}
@@ -0,0 +1,98 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestScaledJob(t *testing.T) {
+	var tests = []struct {
+		name          string
+		scaledJob     string
+		cpu           resource.Quantity
+		memory        resource.Quantity
+		cpuRequest    resource.Quantity
+		memoryRequest resource.Quantity
+		replicas      int32
+		maxReplicas   int32
+		autoscalerMax int32
+		strategy      string
+	}{
+		{
+			name:          "ok",
+			scaledJob:     normalScaledJob,
+			cpu:           resource.MustParse("5"),
+			memory:        resource.MustParse("10Gi"),
+			cpuRequest:    resource.MustParse("2500m"),
+			memoryRequest: resource.MustParse("5Gi"),
+			replicas:      2,
+			maxReplicas:   10,
+			autoscalerMax: 5,
+			strategy:      "accurate",
+		},
+		{
+			name:          "default max replica count",
+			scaledJob:     defaultScaledJob,
+			cpu:           resource.MustParse("10"),
+			memory:        resource.MustParse("12800Mi"),
+			cpuRequest:    resource.MustParse("10"),
+			memoryRequest: resource.MustParse("12800Mi"),
+			replicas:      1,
+			maxReplicas:   100,
+			autoscalerMax: 100,
+			strategy:      "default",
+		},
+		{
+			name:          "without job template",
+			scaledJob:     noTemplateScaledJob,
+			cpu:           resource.MustParse("0"),
+			memory:        resource.MustParse("0"),
+			cpuRequest:    resource.MustParse("0"),
+			memoryRequest: resource.MustParse("0"),
+			replicas:      0,
+			maxReplicas:   0,
+			autoscalerMax: 5,
+			strategy:      "default",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.scaledJob))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+			r.Equalf(test.cpuRequest.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu request value")
+			r.Equalf(test.memoryRequest.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory request value")
+			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+			r.Equalf(test.autoscalerMax, usage.Details.AutoscalerMaxReplicas, "autoscalerMaxReplicas")
+			r.Equalf(test.strategy, usage.Details.Strategy, "strategy")
+			r.Equalf("keda.sh/v1alpha1", usage.Details.Version, "version")
+			r.Equalf("ScaledJob", usage.Details.Kind, "kind")
+		})
+	}
+}
+
+func TestScaledObject(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ResourceQuotaFromYaml([]byte(zeroReplicaScaledObject))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+
+	calculator := NewCalculator()
+	calculator.ObjectCounts = true
+
+	usage, err := calculator.ResourceQuotaFromYaml([]byte(zeroReplicaScaledObject))
+	r.NoError(err)
+	r.Equal(int64(1), quantity(usage, "count/scaledobjects.keda.sh").Value())
+}
@@ -0,0 +1,111 @@
+package calc
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// convertLegacy converts k8s resources of deprecated group versions into their current types. The
+// defaults of the deprecated group versions, which differ from the current ones, are set before the
+// conversion. The api version of the converted object is kept, the returned group version kind is the
+// one of the current type, as the api server stores (and counts) them by that.
+// Currently supported:
+// * apps/v1beta1, apps/v1beta2, extensions/v1beta1 - Deployment
+// * apps/v1beta1, apps/v1beta2 - StatefulSet
+// * apps/v1beta2, extensions/v1beta1 - DaemonSet
+// * apps/v1beta2, extensions/v1beta1 - ReplicaSet
+// * batch/v1beta1 - CronJob
+func convertLegacy(object runtime.Object, gvk *schema.GroupVersionKind) (runtime.Object, *schema.GroupVersionKind, error) {
+	var current runtime.Object
+
+	switch obj := object.(type) {
+	case *extensionsv1beta1.Deployment:
+		setExtensionsDeploymentDefaults(obj)
+
+		current = &appsv1.Deployment{}
+	case *appsv1beta1.Deployment, *appsv1beta2.Deployment:
+		current = &appsv1.Deployment{}
+	case *appsv1beta1.StatefulSet:
+		// apps/v1beta1 statefulsets default to the OnDelete update strategy
+		if obj.Spec.UpdateStrategy.Type == "" {
+			obj.Spec.UpdateStrategy.Type = appsv1beta1.OnDeleteStatefulSetStrategyType
+		}
+
+		current = &appsv1.StatefulSet{}
+	case *appsv1beta2.StatefulSet:
+		current = &appsv1.StatefulSet{}
+	case *extensionsv1beta1.DaemonSet:
+		// extensions/v1beta1 daemonsets default to the OnDelete update strategy
+		if obj.Spec.UpdateStrategy.Type == "" {
+			obj.Spec.UpdateStrategy.Type = extensionsv1beta1.OnDeleteDaemonSetStrategyType
+		}
+
+		current = &appsv1.DaemonSet{}
+	case *appsv1beta2.DaemonSet:
+		current = &appsv1.DaemonSet{}
+	case *extensionsv1beta1.ReplicaSet, *appsv1beta2.ReplicaSet:
+		current = &appsv1.ReplicaSet{}
+	case *batchV1beta1.CronJob:
+		current = &batchV1.CronJob{}
+	default:
+		return object, gvk, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, nil, fmt.Errorf("converting %s %s: %w", gvk.GroupVersion(), gvk.Kind, err)
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, current); err != nil {
+		return nil, nil, fmt.Errorf("converting %s %s: %w", gvk.GroupVersion(), gvk.Kind, err)
+	}
+
+	currentGVK := currentGroupVersion(gvk.Kind).WithKind(gvk.Kind)
+
+	return current, &currentGVK, nil
+}
+
+func currentGroupVersion(kind string) schema.GroupVersion {
+	if kind == "CronJob" {
+		return batchV1.SchemeGroupVersion
+	}
+
+	return appsv1.SchemeGroupVersion
+}
+
+// setExtensionsDeploymentDefaults sets the defaults of extensions/v1beta1 deployments, which surge
+// and can be unavailable by one pod instead of 25%.
+func setExtensionsDeploymentDefaults(deployment *extensionsv1beta1.Deployment) {
+	strategy := &deployment.Spec.Strategy
+
+	if strategy.Type == "" {
+		strategy.Type = extensionsv1beta1.RollingUpdateDeploymentStrategyType
+	}
+
+	if strategy.Type != extensionsv1beta1.RollingUpdateDeploymentStrategyType {
+		return
+	}
+
+	if strategy.RollingUpdate == nil {
+		strategy.RollingUpdate = &extensionsv1beta1.RollingUpdateDeployment{}
+	}
+
+	if strategy.RollingUpdate.MaxUnavailable == nil {
+		maxUnavailable := intstr.FromInt32(1)
+		strategy.RollingUpdate.MaxUnavailable = &maxUnavailable
+	}
+
+	if strategy.RollingUpdate.MaxSurge == nil {
+		maxSurge := intstr.FromInt32(1)
+		strategy.RollingUpdate.MaxSurge = &maxSurge
+	}
+}
@@ -0,0 +1,110 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestLegacyVersions(t *testing.T) {
+	var tests = []struct {
+		name        string
+		manifest    string
+		version     string
+		kind        string
+		cpuRequests resource.Quantity
+		replicas    int32
+		maxReplicas int32
+		strategy    string
+		countName   v1.ResourceName
+	}{
+		{
+			name:        "extensions/v1beta1 deployment",
+			manifest:    extensionsDeployment,
+			version:     "extensions/v1beta1",
+			kind:        "Deployment",
+			cpuRequests: resource.MustParse("2750m"),
+			replicas:    10,
+			maxReplicas: 11,
+			strategy:    "RollingUpdate",
+			countName:   "count/deployments.apps",
+		},
+		{
+			name:        "apps/v1beta2 deployment",
+			manifest:    appsV1beta2Deployment,
+			version:     "apps/v1beta2",
+			kind:        "Deployment",
+			cpuRequests: resource.MustParse("3250m"),
+			replicas:    10,
+			maxReplicas: 13,
+			strategy:    "RollingUpdate",
+			countName:   "count/deployments.apps",
+		},
+		{
+			name:        "apps/v1beta1 statefulset",
+			manifest:    appsV1beta1StatefulSet,
+			version:     "apps/v1beta1",
+			kind:        "StatefulSet",
+			cpuRequests: resource.MustParse("2500m"),
+			replicas:    10,
+			maxReplicas: 10,
+			strategy:    "OnDelete",
+			countName:   "count/statefulsets.apps",
+		},
+		{
+			name:        "extensions/v1beta1 daemonset",
+			manifest:    extensionsDaemonSet,
+			version:     "extensions/v1beta1",
+			kind:        "DaemonSet",
+			cpuRequests: resource.MustParse("250m"),
+			replicas:    1,
+			maxReplicas: 1,
+			strategy:    "OnDelete",
+			countName:   "count/daemonsets.apps",
+		},
+		{
+			name:        "apps/v1beta2 replicaset",
+			manifest:    appsV1beta2ReplicaSet,
+			version:     "apps/v1beta2",
+			kind:        "ReplicaSet",
+			cpuRequests: resource.MustParse("2500m"),
+			replicas:    10,
+			maxReplicas: 10,
+			countName:   "count/replicasets.apps",
+		},
+		{
+			name:        "batch/v1beta1 cronjob",
+			manifest:    batchV1beta1CronJob,
+			version:     "batch/v1beta1",
+			kind:        "CronJob",
+			cpuRequests: resource.MustParse("250m"),
+			replicas:    1,
+			maxReplicas: 1,
+			strategy:    "Allow",
+			countName:   "count/cronjobs.batch",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+			calculator.ObjectCounts = true
+
+			usage, err := calculator.ResourceQuotaFromYaml([]byte(test.manifest))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equal(test.version, usage.Details.Version)
+			r.Equal(test.kind, usage.Details.Kind)
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+			r.Equalf(test.strategy, usage.Details.Strategy, "strategy")
+			r.Equal(int64(1), quantity(usage, test.countName).Value())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package calc
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// AddLimitRange decodes a single yaml document containing a LimitRange and registers its container
+// defaults. The defaults are applied to all containers, which do not specify the resources on their
+// own, the same way the LimitRanger admission plugin does. ErrResourceNotSupported is returned for
+// all other k8s resources.
+// Currently supported:
+// * v1 - LimitRange
+func (c *Calculator) AddLimitRange(yamlData []byte) error {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil && !runtime.IsNotRegisteredError(err) {
+		return fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	limitRange, ok := object.(*v1.LimitRange)
+	if !ok {
+		calcErr := CalculationError{
+			err: ErrResourceNotSupported,
+		}
+
+		if gvk != nil {
+			calcErr.Version = gvk.Version
+			calcErr.Kind = gvk.Kind
+		}
+
+		return calcErr
+	}
+
+	c.limitRanges = append(c.limitRanges, containerDefaults(limitRange))
+
+	return nil
+}
+
+// containerDefaults returns the default requests and limits of all container limits of a
+// LimitRange. Missing defaults are filled in like the API server does, the default limit defaults
+// to max and the default request defaults to the default limit or to min.
+func containerDefaults(limitRange *v1.LimitRange) v1.ResourceRequirements {
+	defaults := v1.ResourceRequirements{
+		Limits:   v1.ResourceList{},
+		Requests: v1.ResourceList{},
+	}
+
+	for i := range limitRange.Spec.Limits {
+		item := limitRange.Spec.Limits[i]
+		if item.Type != v1.LimitTypeContainer {
+			continue
+		}
+
+		limits := v1.ResourceList{}
+		requests := v1.ResourceList{}
+
+		setMissing(limits, item.Default)
+		setMissing(limits, item.Max)
+		setMissing(requests, item.DefaultRequest)
+		setMissing(requests, limits)
+		setMissing(requests, item.Min)
+
+		for name, quantity := range limits {
+			defaults.Limits[name] = quantity
+		}
+
+		for name, quantity := range requests {
+			defaults.Requests[name] = quantity
+		}
+	}
+
+	return defaults
+}
+
+// setLimitRangeDefaults sets the default requests and limits of all registered LimitRanges on
+// containers and init containers, which do not specify them, like the LimitRanger admission plugin
+// does.
+func (c *Calculator) setLimitRangeDefaults(spec *v1.PodSpec) {
+	for _, defaults := range c.limitRanges {
+		for i := range spec.InitContainers {
+			mergeResources(&spec.InitContainers[i].Resources, defaults)
+		}
+
+		for i := range spec.Containers {
+			mergeResources(&spec.Containers[i].Resources, defaults)
+		}
+	}
+}
+
+// mergeResources merges the defaults of a LimitRange into the resources of a container. Requests,
+// which default to the limits of the container, are not overwritten by the LimitRange.
+func mergeResources(resources *v1.ResourceRequirements, defaults v1.ResourceRequirements) {
+	resources.Requests = defaultRequests(*resources)
+
+	if len(defaults.Limits) > 0 && resources.Limits == nil {
+		resources.Limits = v1.ResourceList{}
+	}
+
+	if len(defaults.Requests) > 0 && resources.Requests == nil {
+		resources.Requests = v1.ResourceList{}
+	}
+
+	setMissing(resources.Limits, defaults.Limits)
+	setMissing(resources.Requests, defaults.Requests)
+}
+
+// setMissing copies all resources of src into dst, which are not yet present in dst.
+func setMissing(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		if _, ok := dst[name]; !ok {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
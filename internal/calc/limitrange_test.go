@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestLimitRange(t *testing.T) {
+	var tests = []struct {
+		name           string
+		limitRanges    []string
+		pod            string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+	}{
+		{
+			name:           "without limit range",
+			pod:            noResourcesPod,
+			cpu:            resource.MustParse("0"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("50m"),
+			memoryRequests: resource.MustParse("0"),
+		},
+		{
+			name:           "default and default request",
+			limitRanges:    []string{defaultLimitRange},
+			pod:            noResourcesPod,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("1Gi"),
+			cpuRequests:    resource.MustParse("150m"),
+			memoryRequests: resource.MustParse("512Mi"),
+		},
+		{
+			name:           "defaults from max",
+			limitRanges:    []string{maxLimitRange},
+			pod:            noResourcesPod,
+			cpu:            resource.MustParse("4"),
+			memory:         resource.MustParse("2Gi"),
+			cpuRequests:    resource.MustParse("2050m"),
+			memoryRequests: resource.MustParse("2Gi"),
+		},
+		{
+			name:           "first limit range wins",
+			limitRanges:    []string{defaultLimitRange, maxLimitRange},
+			pod:            noResourcesPod,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("1Gi"),
+			cpuRequests:    resource.MustParse("150m"),
+			memoryRequests: resource.MustParse("512Mi"),
+		},
+		{
+			name:           "requests default to limits before limit range",
+			limitRanges:    []string{defaultLimitRange},
+			pod:            limitsOnlyPod,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("1Gi"),
+			cpuRequests:    resource.MustParse("200m"),
+			memoryRequests: resource.MustParse("1Gi"),
+		},
+		{
+			name:           "resources set in pod spec",
+			limitRanges:    []string{defaultLimitRange},
+			pod:            normalPod,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+
+			for _, limitRange := range test.limitRanges {
+				r.NoError(calculator.AddLimitRange([]byte(limitRange)))
+			}
+
+			usage, err := calculator.ResourceQuotaFromYaml([]byte(test.pod))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
+		})
+	}
+}
+
+func TestAddLimitRange(t *testing.T) {
+	r := require.New(t)
+
+	err := NewCalculator().AddLimitRange([]byte(normalPod))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+
+	err = NewCalculator().AddLimitRange([]byte(unsupportedOpenshiftRoute))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+}
@@ -0,0 +1,132 @@
+package calc
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+)
+
+// AddNode decodes a single yaml document containing a Node and registers it. DaemonSets run a pod on
+// every registered node, which matches their node selector, node affinity and tolerations.
+// Currently supported:
+// * v1 - Node
+// * v1 - List (of Nodes)
+func (c *Calculator) AddNode(yamlData []byte) error {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil {
+		return fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	switch obj := object.(type) {
+	case *v1.Node:
+		c.nodes = append(c.nodes, *obj)
+	case *v1.List:
+		// kubectl get nodes -o yaml returns a list of nodes
+		for i := range obj.Items {
+			if err := c.AddNode(obj.Items[i].Raw); err != nil {
+				return err
+			}
+		}
+	default:
+		return CalculationError{
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+			err:     ErrResourceNotSupported,
+		}
+	}
+
+	return nil
+}
+
+// daemonSetNodes returns the number of nodes, on which the pods of a daemonset run. With registered
+// nodes, the nodes matching the pod spec are counted, otherwise the configured node count is used.
+// Without both, a single node is assumed.
+func (c *Calculator) daemonSetNodes(spec *v1.PodSpec) int32 {
+	if len(c.nodes) == 0 {
+		if c.NodeCount > 0 {
+			return c.NodeCount
+		}
+
+		return 1
+	}
+
+	var nodes int32
+
+	affinity := nodeaffinity.GetRequiredNodeAffinity(&v1.Pod{Spec: *spec})
+	tolerations := daemonSetTolerations(spec)
+
+	for i := range c.nodes {
+		node := &c.nodes[i]
+
+		matches, err := affinity.Match(node)
+		if err != nil {
+			log.Warn().Msgf("node %s: %s", node.Name, err)
+
+			continue
+		}
+
+		if matches && toleratesTaints(tolerations, node.Spec.Taints) {
+			nodes++
+		}
+	}
+
+	return nodes
+}
+
+// daemonSetTolerations returns the tolerations of a daemonset pod. The daemonset controller adds
+// tolerations for the taints of unhealthy or unschedulable nodes to all its pods.
+func daemonSetTolerations(spec *v1.PodSpec) []v1.Toleration {
+	tolerations := append([]v1.Toleration{}, spec.Tolerations...)
+
+	tolerate := func(key string, effect v1.TaintEffect) {
+		tolerations = append(tolerations, v1.Toleration{
+			Key:      key,
+			Operator: v1.TolerationOpExists,
+			Effect:   effect,
+		})
+	}
+
+	tolerate(v1.TaintNodeNotReady, v1.TaintEffectNoExecute)
+	tolerate(v1.TaintNodeUnreachable, v1.TaintEffectNoExecute)
+	tolerate(v1.TaintNodeDiskPressure, v1.TaintEffectNoSchedule)
+	tolerate(v1.TaintNodeMemoryPressure, v1.TaintEffectNoSchedule)
+	tolerate(v1.TaintNodePIDPressure, v1.TaintEffectNoSchedule)
+	tolerate(v1.TaintNodeUnschedulable, v1.TaintEffectNoSchedule)
+
+	if spec.HostNetwork {
+		tolerate(v1.TaintNodeNetworkUnavailable, v1.TaintEffectNoSchedule)
+	}
+
+	return tolerations
+}
+
+// toleratesTaints returns true, if all NoSchedule and NoExecute taints are tolerated. PreferNoSchedule
+// taints do not prevent pods from being scheduled.
+func toleratesTaints(tolerations []v1.Toleration, taints []v1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
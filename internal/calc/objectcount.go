@@ -0,0 +1,79 @@
+package calc
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	countPrefix = "count/"
+)
+
+// objectCounts returns the object counts of a k8s object, keyed by the resource names used in a
+// ResourceQuota. Every object is counted by its count/<resource>.<group> key, core resources with a
+// legacy quota name (e.g. services or secrets) are counted by that name too. Resources creating pods
+// count their peak number of pods (MaxReplicas).
+func objectCounts(object runtime.Object, gvk *schema.GroupVersionKind, usage *ResourceUsage) v1.ResourceList {
+	counts := v1.ResourceList{}
+
+	count := func(name v1.ResourceName, value int64) {
+		quantity := counts[name]
+		quantity.Add(*resource.NewQuantity(value, resource.DecimalSI))
+		counts[name] = quantity
+	}
+
+	plural, _ := meta.UnsafeGuessKindToResource(*gvk)
+
+	countName := v1.ResourceName(countPrefix + plural.Resource)
+	if plural.Group != "" {
+		countName = v1.ResourceName(countPrefix + plural.Resource + "." + plural.Group)
+	}
+
+	if podSpec(object) != nil {
+		count(v1.ResourcePods, int64(usage.Details.MaxReplicas))
+		count(countPrefix+v1.ResourcePods, int64(usage.Details.MaxReplicas))
+	}
+
+	// pvcs are already counted by the calculation, including the ones of volumeClaimTemplates
+	if pvcs, ok := usage.Resources[v1.ResourcePersistentVolumeClaims]; ok {
+		counts[countPrefix+v1.ResourcePersistentVolumeClaims] = pvcs.DeepCopy()
+	}
+
+	// pods and pvcs are counted above
+	if countName != countPrefix+v1.ResourcePods && countName != countPrefix+v1.ResourcePersistentVolumeClaims {
+		count(countName, 1)
+	}
+
+	if plural.Group != "" {
+		return counts
+	}
+
+	switch v1.ResourceName(plural.Resource) {
+	case v1.ResourceServices, v1.ResourceConfigMaps, v1.ResourceSecrets, v1.ResourceReplicationControllers, v1.ResourceQuotas:
+		count(v1.ResourceName(plural.Resource), 1)
+	}
+
+	if svc, ok := object.(*v1.Service); ok {
+		serviceCounts(svc, count)
+	}
+
+	return counts
+}
+
+// serviceCounts counts the load balancers and node ports of a service the same way the quota
+// admission does.
+func serviceCounts(svc *v1.Service, count func(name v1.ResourceName, value int64)) {
+	switch svc.Spec.Type {
+	case v1.ServiceTypeNodePort:
+		count(v1.ResourceServicesNodePorts, int64(len(svc.Spec.Ports)))
+	case v1.ServiceTypeLoadBalancer:
+		count(v1.ResourceServicesLoadBalancers, 1)
+
+		if svc.Spec.AllocateLoadBalancerNodePorts == nil || *svc.Spec.AllocateLoadBalancerNodePorts {
+			count(v1.ResourceServicesNodePorts, int64(len(svc.Spec.Ports)))
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestObjectCounts(t *testing.T) {
+	var tests = []struct {
+		name   string
+		object string
+		counts map[v1.ResourceName]int64
+	}{
+		{
+			name:   "deployment",
+			object: normalDeployment,
+			counts: map[v1.ResourceName]int64{
+				"count/deployments.apps": 1,
+				"pods":                   13,
+				"count/pods":             13,
+			},
+		},
+		{
+			name:   "statefulset with volumeClaimTemplates",
+			object: volumeClaimTemplateStatefulSet,
+			counts: map[v1.ResourceName]int64{
+				"count/statefulsets.apps":      1,
+				"pods":                         3,
+				"count/pods":                   3,
+				"persistentvolumeclaims":       6,
+				"count/persistentvolumeclaims": 6,
+			},
+		},
+		{
+			name:   "pod",
+			object: normalPod,
+			counts: map[v1.ResourceName]int64{
+				"pods":       1,
+				"count/pods": 1,
+			},
+		},
+		{
+			name:   "persistent volume claim",
+			object: normalPersistentVolumeClaim,
+			counts: map[v1.ResourceName]int64{
+				"persistentvolumeclaims":       1,
+				"count/persistentvolumeclaims": 1,
+			},
+		},
+		{
+			name:   "service",
+			object: service,
+			counts: map[v1.ResourceName]int64{
+				"services":       1,
+				"count/services": 1,
+			},
+		},
+		{
+			name:   "load balancer service",
+			object: loadBalancerService,
+			counts: map[v1.ResourceName]int64{
+				"services":               1,
+				"count/services":         1,
+				"services.loadbalancers": 1,
+				"services.nodeports":     2,
+			},
+		},
+		{
+			name:   "config map",
+			object: configMap,
+			counts: map[v1.ResourceName]int64{
+				"configmaps":       1,
+				"count/configmaps": 1,
+			},
+		},
+		{
+			name:   "secret",
+			object: secret,
+			counts: map[v1.ResourceName]int64{
+				"secrets":       1,
+				"count/secrets": 1,
+			},
+		},
+		{
+			name:   "unregistered kind",
+			object: unsupportedOpenshiftRoute,
+			counts: map[v1.ResourceName]int64{
+				"count/routes": 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+			calculator.ObjectCounts = true
+
+			usage, err := calculator.ResourceQuotaFromYaml([]byte(test.object))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			for name, expected := range test.counts {
+				r.Equalf(expected, quantity(usage, name).Value(), "%s value", name)
+			}
+
+			for name := range usage.Resources {
+				if _, ok := test.counts[name]; !ok {
+					r.NotContains(name, "count/", "unexpected count")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package calc
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	storageClassSuffix         = ".storageclass.storage.k8s.io/"
+	betaStorageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+)
+
+func (c *Calculator) persistentVolumeClaim(pvc v1.PersistentVolumeClaim) *ResourceUsage {
+	resourceUsage := ResourceUsage{
+		Resources: persistentVolumeClaimResources(&pvc),
+		Details: Details{
+			Version:     pvc.APIVersion,
+			Kind:        pvc.Kind,
+			Name:        pvc.Name,
+			Strategy:    "",
+			Replicas:    0,
+			MaxReplicas: 0,
+		},
+	}
+
+	return &resourceUsage
+}
+
+// persistentVolumeClaimResources calculates the storage resources of a single pvc, keyed by the resource
+// names used in a ResourceQuota. If the pvc has a storage class, the resources are also reported per
+// storage class.
+func persistentVolumeClaimResources(pvc *v1.PersistentVolumeClaim) v1.ResourceList {
+	storage := pvc.Spec.Resources.Requests.Storage().DeepCopy()
+	count := resource.MustParse("1")
+
+	resources := v1.ResourceList{
+		v1.ResourceRequestsStorage:        storage,
+		v1.ResourcePersistentVolumeClaims: count,
+	}
+
+	if class := storageClass(pvc); class != "" {
+		resources[v1.ResourceName(class+storageClassSuffix+string(v1.ResourceRequestsStorage))] = storage.DeepCopy()
+		resources[v1.ResourceName(class+storageClassSuffix+string(v1.ResourcePersistentVolumeClaims))] = count
+	}
+
+	return resources
+}
+
+// storageClass returns the storage class of a pvc. Like the quota admission, the deprecated beta
+// annotation takes precedence over the storageClassName field.
+func storageClass(pvc *v1.PersistentVolumeClaim) string {
+	if class, ok := pvc.Annotations[betaStorageClassAnnotation]; ok {
+		return class
+	}
+
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+
+	return ""
+}
@@ -0,0 +1,62 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPersistentVolumeClaim(t *testing.T) {
+	var tests = []struct {
+		name      string
+		pvc       string
+		resources v1.ResourceList
+	}{
+		{
+			name: "ok",
+			pvc:  normalPersistentVolumeClaim,
+			resources: v1.ResourceList{
+				"requests.storage":                                        resource.MustParse("8Gi"),
+				"persistentvolumeclaims":                                  resource.MustParse("1"),
+				"gold.storageclass.storage.k8s.io/requests.storage":       resource.MustParse("8Gi"),
+				"gold.storageclass.storage.k8s.io/persistentvolumeclaims": resource.MustParse("1"),
+			},
+		},
+		{
+			name: "beta storage class annotation",
+			pvc:  annotatedPersistentVolumeClaim,
+			resources: v1.ResourceList{
+				"requests.storage":       resource.MustParse("8Gi"),
+				"persistentvolumeclaims": resource.MustParse("1"),
+				"silver.storageclass.storage.k8s.io/requests.storage":       resource.MustParse("8Gi"),
+				"silver.storageclass.storage.k8s.io/persistentvolumeclaims": resource.MustParse("1"),
+			},
+		},
+		{
+			name: "no storage class",
+			pvc:  noStorageClassPersistentVolumeClaim,
+			resources: v1.ResourceList{
+				"requests.storage":       resource.MustParse("8Gi"),
+				"persistentvolumeclaims": resource.MustParse("1"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.pvc))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Len(usage.Resources, len(test.resources))
+
+			for name, expected := range test.resources {
+				r.Equalf(expected.Value(), quantity(usage, name).Value(), "%s value", name)
+			}
+		})
+	}
+}
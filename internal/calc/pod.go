@@ -2,19 +2,18 @@ package calc
 
 import v1 "k8s.io/api/core/v1"
 
-func pod(pod v1.Pod) *ResourceUsage {
-	cpu, memory := podResources(&pod.Spec)
+func (c *Calculator) pod(pod v1.Pod) *ResourceUsage {
+	resources := c.podResources(&pod.Spec)
 
 	resourceUsage := ResourceUsage{
-		CPU:    cpu,
-		Memory: memory,
+		Resources: resources,
 		Details: Details{
 			Version:     pod.APIVersion,
 			Kind:        pod.Kind,
 			Name:        pod.Name,
 			Strategy:    "",
-			Replicas:    0,
-			MaxReplicas: 0,
+			Replicas:    1,
+			MaxReplicas: 1,
 		},
 	}
 
@@ -5,24 +5,31 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestPod(t *testing.T) {
 	var tests = []struct {
-		name        string
-		pod         string
-		cpu         resource.Quantity
-		memory      resource.Quantity
-		replicas    int32
-		maxReplicas int32
-		strategy    appsv1.StatefulSetUpdateStrategyType
+		name           string
+		pod            string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+		strategy       appsv1.StatefulSetUpdateStrategyType
 	}{
 		{
-			name:   "ok",
-			pod:    normalPod,
-			cpu:    resource.MustParse("1"),
-			memory: resource.MustParse("4Gi"),
+			name:           "ok",
+			replicas:       1,
+			maxReplicas:    1,
+			pod:            normalPod,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
 		},
 	}
 
@@ -35,8 +42,10 @@ func TestPod(t *testing.T) {
 				r.NoError(err)
 				r.NotEmpty(usage)
 
-				r.Equalf(test.cpu.Value(), usage.CPU.Value(), "cpu value")
-				r.Equalf(test.memory.Value(), usage.Memory.Value(), "memory value")
+				r.Equalf(test.cpu.Value(), quantity(usage, v1.ResourceLimitsCPU).Value(), "cpu value")
+				r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+				r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+				r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
 				r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
 				r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
 				r.Equalf(string(test.strategy), usage.Details.Strategy, "strategy")
@@ -0,0 +1,118 @@
+package calc
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	mebi = 1 << 20
+	gibi = 1 << 30
+)
+
+// QuotaOptions contains the options to generate a ResourceQuota.
+type QuotaOptions struct {
+	Name      string
+	Namespace string
+	// Headroom increases all resources by the given percentage.
+	Headroom int64
+	// Round rounds all resources up to friendly units (e.g. 1700m to 2 or 15104Mi to 15Gi).
+	Round bool
+}
+
+// ResourceQuota generates a ResourceQuota, which contains all resources as hard limits.
+func ResourceQuota(resources v1.ResourceList, opts QuotaOptions) *v1.ResourceQuota {
+	hard := make(v1.ResourceList, len(resources))
+
+	for name, quantity := range resources {
+		if opts.Headroom != 0 {
+			quantity = addHeadroom(quantity, opts.Headroom)
+		}
+
+		switch {
+		case opts.Round:
+			quantity = roundUp(name, quantity)
+		case baseResourceName(name) != v1.ResourceCPU:
+			// only cpu can be fractional, bytes and object counts are whole numbers
+			quantity = *resource.NewQuantity(quantity.Value(), quantity.Format)
+		}
+
+		hard[name] = quantity
+	}
+
+	return &v1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ResourceQuota",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.Name,
+			Namespace: opts.Namespace,
+		},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+}
+
+// addHeadroom increases a quantity by percent, rounded up to the next milli unit.
+func addHeadroom(quantity resource.Quantity, percent int64) resource.Quantity {
+	return mulQuantity(quantity, 100+percent, 100)
+}
+
+// roundUp rounds a quantity up to a friendly unit. cpu is rounded to 100m below one core and to whole
+// cores above, byte quantities are rounded to Mi below 1Gi and to Gi above, everything else is rounded
+// to whole numbers.
+func roundUp(name v1.ResourceName, quantity resource.Quantity) resource.Quantity {
+	switch baseName := baseResourceName(name); {
+	case baseName == v1.ResourceCPU:
+		milli := quantity.MilliValue()
+		if milli < 1000 {
+			return *resource.NewMilliQuantity(ceil(milli, 100), resource.DecimalSI)
+		}
+
+		return *resource.NewQuantity(ceil(milli, 1000)/1000, resource.DecimalSI)
+	case isByteResource(baseName):
+		bytes := quantity.Value()
+		if bytes < gibi {
+			return *resource.NewQuantity(ceil(bytes, mebi), resource.BinarySI)
+		}
+
+		return *resource.NewQuantity(ceil(bytes, gibi), resource.BinarySI)
+	default:
+		return *resource.NewQuantity(quantity.Value(), resource.DecimalSI)
+	}
+}
+
+// ceil rounds value up to the next multiple of unit.
+func ceil(value, unit int64) int64 {
+	return (value + unit - 1) / unit * unit
+}
+
+// baseResourceName strips the requests/limits prefix and the storage class of a quota resource name,
+// e.g. requests.cpu becomes cpu and gold.storageclass.storage.k8s.io/requests.storage becomes storage.
+func baseResourceName(name v1.ResourceName) v1.ResourceName {
+	baseName := string(name)
+
+	if i := strings.Index(baseName, storageClassSuffix); i >= 0 {
+		baseName = baseName[i+len(storageClassSuffix):]
+	}
+
+	baseName = strings.TrimPrefix(baseName, v1.DefaultResourceRequestsPrefix)
+	baseName = strings.TrimPrefix(baseName, limitsPrefix)
+
+	return v1.ResourceName(baseName)
+}
+
+// isByteResource returns true for resources measured in bytes.
+func isByteResource(name v1.ResourceName) bool {
+	switch name {
+	case v1.ResourceMemory, v1.ResourceEphemeralStorage, v1.ResourceStorage:
+		return true
+	default:
+		return strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix)
+	}
+}
@@ -0,0 +1,102 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceQuota(t *testing.T) {
+	resources := v1.ResourceList{
+		v1.ResourceRequestsCPU:                              resource.MustParse("3500m"),
+		v1.ResourceLimitsCPU:                                resource.MustParse("850m"),
+		v1.ResourceRequestsMemory:                           resource.MustParse("6848Mi"),
+		v1.ResourceLimitsMemory:                             resource.MustParse("700Mi"),
+		v1.ResourceRequestsStorage:                          resource.MustParse("33Gi"),
+		"gold.storageclass.storage.k8s.io/requests.storage": resource.MustParse("30Gi"),
+		v1.ResourcePods:                                     resource.MustParse("14"),
+	}
+
+	var tests = []struct {
+		name string
+		opts QuotaOptions
+		hard map[v1.ResourceName]string
+	}{
+		{
+			name: "without headroom",
+			opts: QuotaOptions{},
+			hard: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:                              "3500m",
+				v1.ResourceLimitsCPU:                                "850m",
+				v1.ResourceRequestsMemory:                           "6848Mi",
+				v1.ResourceLimitsMemory:                             "700Mi",
+				v1.ResourceRequestsStorage:                          "33Gi",
+				"gold.storageclass.storage.k8s.io/requests.storage": "30Gi",
+				v1.ResourcePods:                                     "14",
+			},
+		},
+		{
+			name: "headroom",
+			opts: QuotaOptions{Headroom: 10},
+			hard: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:                              "3850m",
+				v1.ResourceLimitsCPU:                                "935m",
+				v1.ResourceRequestsMemory:                           "7898713293",
+				v1.ResourceLimitsMemory:                             "770Mi",
+				v1.ResourceRequestsStorage:                          "38976828212",
+				"gold.storageclass.storage.k8s.io/requests.storage": "33Gi",
+				v1.ResourcePods:                                     "16",
+			},
+		},
+		{
+			name: "round",
+			opts: QuotaOptions{Round: true},
+			hard: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:                              "4",
+				v1.ResourceLimitsCPU:                                "900m",
+				v1.ResourceRequestsMemory:                           "7Gi",
+				v1.ResourceLimitsMemory:                             "700Mi",
+				v1.ResourceRequestsStorage:                          "33Gi",
+				"gold.storageclass.storage.k8s.io/requests.storage": "30Gi",
+				v1.ResourcePods:                                     "14",
+			},
+		},
+		{
+			name: "headroom and round",
+			opts: QuotaOptions{Headroom: 10, Round: true},
+			hard: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:                              "4",
+				v1.ResourceLimitsCPU:                                "1",
+				v1.ResourceRequestsMemory:                           "8Gi",
+				v1.ResourceLimitsMemory:                             "770Mi",
+				v1.ResourceRequestsStorage:                          "37Gi",
+				"gold.storageclass.storage.k8s.io/requests.storage": "33Gi",
+				v1.ResourcePods:                                     "16",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			test.opts.Name = "quota"
+			test.opts.Namespace = "myapp"
+
+			quota := ResourceQuota(resources, test.opts)
+
+			r.Equal("v1", quota.APIVersion)
+			r.Equal("ResourceQuota", quota.Kind)
+			r.Equal("quota", quota.Name)
+			r.Equal("myapp", quota.Namespace)
+			r.Len(quota.Spec.Hard, len(test.hard))
+
+			for name, expected := range test.hard {
+				value := quota.Spec.Hard[name]
+				r.Equalf(expected, value.String(), "%s value", name)
+			}
+		})
+	}
+}
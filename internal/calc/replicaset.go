@@ -0,0 +1,39 @@
+package calc
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// calculates the resources a single replicaset needs. Replicas are taken into account, a replicaset
+// has no update strategy.
+func (c *Calculator) replicaSet(r appsv1.ReplicaSet) *ResourceUsage {
+	var (
+		replicas int32
+	)
+
+	// https://github.com/kubernetes/api/blob/v0.29.15/apps/v1/types.go#L896
+	if r.Spec.Replicas != nil {
+		replicas = *r.Spec.Replicas
+	} else {
+		replicas = 1
+	}
+
+	scaled, autoscalerMax := c.scaledReplicas(r.Kind, r.Name, replicas)
+
+	resources := c.podResources(&r.Spec.Template.Spec)
+	mulResourceList(resources, int64(scaled))
+
+	resourceUsage := ResourceUsage{
+		Resources: resources,
+		Details: Details{
+			Version:               r.APIVersion,
+			Kind:                  r.Kind,
+			Name:                  r.Name,
+			Replicas:              replicas,
+			MaxReplicas:           scaled,
+			AutoscalerMaxReplicas: autoscalerMax,
+		},
+	}
+
+	return &resourceUsage
+}
@@ -0,0 +1,60 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestReplicaSet(t *testing.T) {
+	var tests = []struct {
+		name           string
+		replicaset     string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+	}{
+		{
+			name:           "ok",
+			replicaset:     normalReplicaSet,
+			cpu:            resource.MustParse("3"),
+			memory:         resource.MustParse("12Gi"),
+			cpuRequests:    resource.MustParse("750m"),
+			memoryRequests: resource.MustParse("6Gi"),
+			replicas:       3,
+			maxReplicas:    3,
+		},
+		{
+			name:           "no replicas",
+			replicaset:     noReplicasReplicaSet,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+			replicas:       1,
+			maxReplicas:    1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.replicaset))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
+			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+		})
+	}
+}
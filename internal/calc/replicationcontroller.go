@@ -0,0 +1,42 @@
+package calc
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// calculates the resources a single replicationcontroller needs. Replicas are taken into account, a
+// replicationcontroller without a pod template does not create any pods.
+func (c *Calculator) replicationController(r v1.ReplicationController) *ResourceUsage {
+	var (
+		replicas  int32
+		resources = v1.ResourceList{}
+	)
+
+	// https://github.com/kubernetes/api/blob/v0.29.15/core/v1/types.go#L5101
+	if r.Spec.Replicas != nil {
+		replicas = *r.Spec.Replicas
+	} else {
+		replicas = 1
+	}
+
+	scaled, autoscalerMax := c.scaledReplicas(r.Kind, r.Name, replicas)
+
+	if r.Spec.Template != nil {
+		resources = c.podResources(&r.Spec.Template.Spec)
+		mulResourceList(resources, int64(scaled))
+	}
+
+	resourceUsage := ResourceUsage{
+		Resources: resources,
+		Details: Details{
+			Version:               r.APIVersion,
+			Kind:                  r.Kind,
+			Name:                  r.Name,
+			Replicas:              replicas,
+			MaxReplicas:           scaled,
+			AutoscalerMaxReplicas: autoscalerMax,
+		},
+	}
+
+	return &resourceUsage
+}
@@ -0,0 +1,72 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestReplicationController(t *testing.T) {
+	var tests = []struct {
+		name                  string
+		replicationcontroller string
+		cpu                   resource.Quantity
+		memory                resource.Quantity
+		cpuRequests           resource.Quantity
+		memoryRequests        resource.Quantity
+		replicas              int32
+		maxReplicas           int32
+	}{
+		{
+			name:                  "ok",
+			replicationcontroller: normalReplicationController,
+			cpu:                   resource.MustParse("2"),
+			memory:                resource.MustParse("8Gi"),
+			cpuRequests:           resource.MustParse("500m"),
+			memoryRequests:        resource.MustParse("4Gi"),
+			replicas:              2,
+			maxReplicas:           2,
+		},
+		{
+			name:                  "no replicas",
+			replicationcontroller: noReplicasReplicationController,
+			cpu:                   resource.MustParse("1"),
+			memory:                resource.MustParse("4Gi"),
+			cpuRequests:           resource.MustParse("250m"),
+			memoryRequests:        resource.MustParse("2Gi"),
+			replicas:              1,
+			maxReplicas:           1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.replicationcontroller))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
+			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+		})
+	}
+}
+
+func TestReplicationControllerWithoutTemplate(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(noTemplateReplicationController))
+	r.NoError(err)
+	r.NotNil(usage)
+
+	r.Empty(usage.Resources)
+	r.Nil(usage.PodResources)
+	r.Equal(int32(2), usage.Details.Replicas)
+}
@@ -0,0 +1,68 @@
+package calc
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// AddRuntimeClass decodes a single yaml document containing a RuntimeClass and registers its pod
+// overhead. The overhead is added to all pods referencing the RuntimeClass by runtimeClassName, which
+// do not specify an overhead on their own.
+// Currently supported:
+// * node.k8s.io/v1 - RuntimeClass
+// * node.k8s.io/v1beta1 - RuntimeClass
+func (c *Calculator) AddRuntimeClass(yamlData []byte) error {
+	object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil {
+		return fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	switch obj := object.(type) {
+	case *nodev1.RuntimeClass:
+		var overhead v1.ResourceList
+
+		if obj.Overhead != nil {
+			overhead = obj.Overhead.PodFixed
+		}
+
+		c.runtimeClasses[obj.Name] = overhead
+	case *nodev1beta1.RuntimeClass:
+		var overhead v1.ResourceList
+
+		if obj.Overhead != nil {
+			overhead = obj.Overhead.PodFixed
+		}
+
+		c.runtimeClasses[obj.Name] = overhead
+	default:
+		return CalculationError{
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+			err:     ErrResourceNotSupported,
+		}
+	}
+
+	return nil
+}
+
+// setOverhead sets the pod overhead from the referenced RuntimeClass, like the RuntimeClass admission
+// plugin does. An overhead which is already set is left as it is.
+func (c *Calculator) setOverhead(spec *v1.PodSpec) {
+	if spec.RuntimeClassName == nil || spec.Overhead != nil {
+		return
+	}
+
+	overhead, ok := c.runtimeClasses[*spec.RuntimeClassName]
+	if !ok {
+		log.Warn().Msgf("runtime class %q not found, pod overhead is not taken into account", *spec.RuntimeClassName)
+
+		return
+	}
+
+	spec.Overhead = overhead.DeepCopy()
+}
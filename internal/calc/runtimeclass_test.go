@@ -0,0 +1,78 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestRuntimeClass(t *testing.T) {
+	var tests = []struct {
+		name           string
+		runtimeClasses []string
+		pod            string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+	}{
+		{
+			name:           "overhead from runtime class",
+			runtimeClasses: []string{kataRuntimeClass},
+			pod:            runtimeClassPod,
+			cpu:            resource.MustParse("1250m"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("500m"),
+			memoryRequests: resource.MustParse("2176Mi"),
+		},
+		{
+			name:           "unknown runtime class",
+			pod:            runtimeClassPod,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("0"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+		},
+		{
+			name:           "overhead set in pod spec",
+			runtimeClasses: []string{kataRuntimeClass},
+			pod:            overheadPod,
+			cpu:            resource.MustParse("1100m"),
+			memory:         resource.MustParse("4160Mi"),
+			cpuRequests:    resource.MustParse("350m"),
+			memoryRequests: resource.MustParse("2112Mi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			calculator := NewCalculator()
+
+			for _, runtimeClass := range test.runtimeClasses {
+				r.NoError(calculator.AddRuntimeClass([]byte(runtimeClass)))
+			}
+
+			usage, err := calculator.ResourceQuotaFromYaml([]byte(test.pod))
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
+		})
+	}
+}
+
+func TestAddRuntimeClass(t *testing.T) {
+	r := require.New(t)
+
+	err := NewCalculator().AddRuntimeClass([]byte(normalPod))
+	r.Error(err)
+	r.True(errors.Is(err, ErrResourceNotSupported))
+}
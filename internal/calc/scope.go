@@ -0,0 +1,332 @@
+package calc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// PodDetails contains the properties of the pods a k8s resource creates, which are used to match the
+// scopes of a ResourceQuota.
+type PodDetails struct {
+	QOSClass                  v1.PodQOSClass
+	Terminating               bool
+	PriorityClassName         string
+	CrossNamespacePodAffinity bool
+}
+
+// ScopeUsage contains the summed resources of all pods, which match a single ResourceQuota scope.
+type ScopeUsage struct {
+	Selector  v1.ScopedResourceSelectorRequirement
+	Resources v1.ResourceList
+}
+
+// String returns the scope name, followed by the values of the selector (e.g. PriorityClass=high).
+func (s ScopeUsage) String() string {
+	if len(s.Selector.Values) == 0 {
+		return string(s.Selector.ScopeName)
+	}
+
+	return fmt.Sprintf("%s=%s", s.Selector.ScopeName, strings.Join(s.Selector.Values, ","))
+}
+
+// ScopeTotals sums up the resource usage per ResourceQuota scope, the same way a quota with this
+// single scope would track it. Terminating, NotTerminating, BestEffort and NotBestEffort are always
+// returned, CrossNamespacePodAffinity only if it matches any pods. For the PriorityClass scope, one
+// total per priority class name found in the usage is returned.
+func ScopeTotals(usage []*ResourceUsage) []ScopeUsage {
+	selectors := []v1.ScopedResourceSelectorRequirement{
+		{ScopeName: v1.ResourceQuotaScopeTerminating, Operator: v1.ScopeSelectorOpExists},
+		{ScopeName: v1.ResourceQuotaScopeNotTerminating, Operator: v1.ScopeSelectorOpExists},
+		{ScopeName: v1.ResourceQuotaScopeBestEffort, Operator: v1.ScopeSelectorOpExists},
+		{ScopeName: v1.ResourceQuotaScopeNotBestEffort, Operator: v1.ScopeSelectorOpExists},
+	}
+
+	optional := []v1.ScopedResourceSelectorRequirement{
+		{ScopeName: v1.ResourceQuotaScopeCrossNamespacePodAffinity, Operator: v1.ScopeSelectorOpExists},
+	}
+
+	for _, name := range priorityClassNames(usage) {
+		optional = append(optional, v1.ScopedResourceSelectorRequirement{
+			ScopeName: v1.ResourceQuotaScopePriorityClass,
+			Operator:  v1.ScopeSelectorOpIn,
+			Values:    []string{name},
+		})
+	}
+
+	totals := make([]ScopeUsage, 0, len(selectors)+len(optional))
+
+	for i, selector := range append(selectors, optional...) {
+		total := ScopeUsage{
+			Selector:  selector,
+			Resources: v1.ResourceList{},
+		}
+
+		matched := false
+		scope := []v1.ScopedResourceSelectorRequirement{selector}
+
+		for _, u := range usage {
+			if matchesScopes(scope, &u.Details) {
+				addResourceList(total.Resources, scopedResources(scope, u.Resources))
+
+				matched = true
+			}
+		}
+
+		if i >= len(selectors) && !matched {
+			continue
+		}
+
+		totals = append(totals, total)
+	}
+
+	return totals
+}
+
+// priorityClassNames returns the sorted, distinct priority class names of all pods.
+func priorityClassNames(usage []*ResourceUsage) []string {
+	found := make(map[string]bool)
+
+	for _, u := range usage {
+		if u.Details.Pod != nil && u.Details.Pod.PriorityClassName != "" {
+			found[u.Details.Pod.PriorityClassName] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+
+	for name := range found {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// podDetails returns the properties of a pod, which are used to match the scopes of a ResourceQuota. Like
+// in the quota admission, only the activeDeadlineSeconds of the pod spec makes a pod Terminating, the
+// activeDeadlineSeconds of a job is not copied to its pods.
+func podDetails(podSpec *v1.PodSpec) *PodDetails {
+	return &PodDetails{
+		QOSClass:                  qosClass(podSpec),
+		Terminating:               podSpec.ActiveDeadlineSeconds != nil && *podSpec.ActiveDeadlineSeconds >= 0,
+		PriorityClassName:         podSpec.PriorityClassName,
+		CrossNamespacePodAffinity: crossNamespacePodAffinity(podSpec),
+	}
+}
+
+// qosClass returns the QoS class of a pod, the same way the kubelet calculates it.
+func qosClass(podSpec *v1.PodSpec) v1.PodQOSClass {
+	var (
+		requests     = v1.ResourceList{}
+		limits       = v1.ResourceList{}
+		isGuaranteed = true
+	)
+
+	containers := make([]v1.Container, 0, len(podSpec.InitContainers)+len(podSpec.Containers))
+	containers = append(containers, podSpec.InitContainers...)
+	containers = append(containers, podSpec.Containers...)
+
+	for i := range containers {
+		containerRequests := defaultRequests(containers[i].Resources)
+		containerLimits := containers[i].Resources.Limits
+		limitsFound := 0
+
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			request := containerRequests[name]
+			limit := containerLimits[name]
+
+			if request.Sign() > 0 {
+				addResourceList(requests, v1.ResourceList{name: request})
+			}
+
+			if limit.Sign() > 0 {
+				addResourceList(limits, v1.ResourceList{name: limit})
+				limitsFound++
+			}
+		}
+
+		if limitsFound < 2 {
+			isGuaranteed = false
+		}
+	}
+
+	if len(requests) == 0 && len(limits) == 0 {
+		return v1.PodQOSBestEffort
+	}
+
+	for name, request := range requests {
+		if limit, ok := limits[name]; !ok || limit.Cmp(request) != 0 {
+			isGuaranteed = false
+		}
+	}
+
+	if isGuaranteed && len(requests) == len(limits) {
+		return v1.PodQOSGuaranteed
+	}
+
+	return v1.PodQOSBurstable
+}
+
+// crossNamespacePodAffinity returns true, if a pod has a pod (anti) affinity term, which selects
+// pods of other namespaces.
+func crossNamespacePodAffinity(podSpec *v1.PodSpec) bool {
+	if podSpec.Affinity == nil {
+		return false
+	}
+
+	var terms []v1.PodAffinityTerm
+
+	if affinity := podSpec.Affinity.PodAffinity; affinity != nil {
+		terms = append(terms, affinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+
+		for i := range affinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, affinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm)
+		}
+	}
+
+	if antiAffinity := podSpec.Affinity.PodAntiAffinity; antiAffinity != nil {
+		terms = append(terms, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+
+		for i := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm)
+		}
+	}
+
+	for i := range terms {
+		if len(terms[i].Namespaces) > 0 || terms[i].NamespaceSelector != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scopeSelectors returns all scopes of a ResourceQuota as scope selectors.
+func scopeSelectors(quota *v1.ResourceQuota) []v1.ScopedResourceSelectorRequirement {
+	selectors := make([]v1.ScopedResourceSelectorRequirement, 0, len(quota.Spec.Scopes))
+
+	for _, scope := range quota.Spec.Scopes {
+		selectors = append(selectors, v1.ScopedResourceSelectorRequirement{
+			ScopeName: scope,
+			Operator:  v1.ScopeSelectorOpExists,
+		})
+	}
+
+	if quota.Spec.ScopeSelector != nil {
+		selectors = append(selectors, quota.Spec.ScopeSelector.MatchExpressions...)
+	}
+
+	return selectors
+}
+
+// matchesScopes returns true, if the resource usage is tracked by a ResourceQuota with the given
+// scopes. Scoped quotas only track pods.
+func matchesScopes(selectors []v1.ScopedResourceSelectorRequirement, details *Details) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+
+	if details.Pod == nil {
+		return false
+	}
+
+	for _, selector := range selectors {
+		if !matchesScope(selector, details.Pod) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// scopedResources returns the resources, which are tracked by a ResourceQuota with the given scopes.
+// Scoped quotas only track the resources of pods, quotas with the BestEffort scope only the number
+// of pods.
+func scopedResources(selectors []v1.ScopedResourceSelectorRequirement, resources v1.ResourceList) v1.ResourceList {
+	if len(selectors) == 0 {
+		return resources
+	}
+
+	bestEffort := false
+
+	for _, selector := range selectors {
+		if selector.ScopeName == v1.ResourceQuotaScopeBestEffort {
+			bestEffort = true
+		}
+	}
+
+	scoped := v1.ResourceList{}
+
+	for name, quantity := range resources {
+		if isPodCount(name) || (!bestEffort && isPodResource(name)) {
+			scoped[name] = quantity
+		}
+	}
+
+	return scoped
+}
+
+func isPodCount(name v1.ResourceName) bool {
+	return name == v1.ResourcePods || name == countPrefix+v1.ResourcePods
+}
+
+// isPodResource returns true for the compute resources of pods, which includes extended resources
+// and huge pages, but not the storage of persistent volume claims.
+func isPodResource(name v1.ResourceName) bool {
+	switch name {
+	case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+		return true
+	case v1.ResourceRequestsStorage:
+		return false
+	}
+
+	return strings.HasPrefix(string(name), v1.DefaultResourceRequestsPrefix) || strings.HasPrefix(string(name), limitsPrefix)
+}
+
+func matchesScope(selector v1.ScopedResourceSelectorRequirement, pod *PodDetails) bool {
+	switch selector.ScopeName {
+	case v1.ResourceQuotaScopeTerminating:
+		return pod.Terminating
+	case v1.ResourceQuotaScopeNotTerminating:
+		return !pod.Terminating
+	case v1.ResourceQuotaScopeBestEffort:
+		return pod.QOSClass == v1.PodQOSBestEffort
+	case v1.ResourceQuotaScopeNotBestEffort:
+		return pod.QOSClass != v1.PodQOSBestEffort
+	case v1.ResourceQuotaScopeCrossNamespacePodAffinity:
+		return pod.CrossNamespacePodAffinity
+	case v1.ResourceQuotaScopePriorityClass:
+		return matchesPriorityClass(selector, pod.PriorityClassName)
+	default:
+		return false
+	}
+}
+
+// matchesPriorityClass matches the priority class of a pod like a label selector on the label
+// PriorityClass, which is only set if the pod has a priority class.
+func matchesPriorityClass(selector v1.ScopedResourceSelectorRequirement, priorityClassName string) bool {
+	contains := false
+
+	for _, value := range selector.Values {
+		if value == priorityClassName {
+			contains = true
+		}
+	}
+
+	switch selector.Operator {
+	case v1.ScopeSelectorOpIn:
+		return priorityClassName != "" && contains
+	case v1.ScopeSelectorOpNotIn:
+		return priorityClassName == "" || !contains
+	case v1.ScopeSelectorOpExists:
+		return priorityClassName != ""
+	case v1.ScopeSelectorOpDoesNotExist:
+		return priorityClassName == ""
+	default:
+		return false
+	}
+}
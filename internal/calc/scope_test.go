@@ -0,0 +1,273 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQOSClass(t *testing.T) {
+	burstable := container("burstable", "100m", "128Mi")
+	burstable.Resources.Limits = nil
+
+	limitsOnly := container("limits-only", "1", "1Gi")
+	limitsOnly.Resources.Requests = nil
+
+	cpuOnly := container("cpu-only", "1", "1Gi")
+	delete(cpuOnly.Resources.Limits, v1.ResourceMemory)
+	delete(cpuOnly.Resources.Requests, v1.ResourceMemory)
+
+	var tests = []struct {
+		name    string
+		podSpec v1.PodSpec
+		qos     v1.PodQOSClass
+	}{
+		{
+			name:    "no resources",
+			podSpec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+			qos:     v1.PodQOSBestEffort,
+		},
+		{
+			name:    "requests equal limits",
+			podSpec: v1.PodSpec{Containers: []v1.Container{container("app", "1", "1Gi")}},
+			qos:     v1.PodQOSGuaranteed,
+		},
+		{
+			name:    "requests default to limits",
+			podSpec: v1.PodSpec{Containers: []v1.Container{limitsOnly}},
+			qos:     v1.PodQOSGuaranteed,
+		},
+		{
+			name:    "requests only",
+			podSpec: v1.PodSpec{Containers: []v1.Container{burstable}},
+			qos:     v1.PodQOSBurstable,
+		},
+		{
+			name:    "cpu only",
+			podSpec: v1.PodSpec{Containers: []v1.Container{cpuOnly}},
+			qos:     v1.PodQOSBurstable,
+		},
+		{
+			name: "burstable init container",
+			podSpec: v1.PodSpec{
+				InitContainers: []v1.Container{burstable},
+				Containers:     []v1.Container{container("app", "1", "1Gi")},
+			},
+			qos: v1.PodQOSBurstable,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			r.Equal(test.qos, qosClass(&test.podSpec))
+		})
+	}
+}
+
+func TestMatchesScopes(t *testing.T) {
+	deadline := int64(600)
+
+	guaranteed := podDetails(&v1.PodSpec{
+		Containers:        []v1.Container{container("app", "1", "1Gi")},
+		PriorityClassName: "high",
+	})
+	bestEffort := podDetails(&v1.PodSpec{
+		Containers:            []v1.Container{{Name: "job"}},
+		ActiveDeadlineSeconds: &deadline,
+	})
+
+	var tests = []struct {
+		name      string
+		selectors []v1.ScopedResourceSelectorRequirement
+		details   Details
+		matches   bool
+	}{
+		{
+			name:    "no scopes",
+			details: Details{Kind: "ConfigMap"},
+			matches: true,
+		},
+		{
+			name: "scoped quota without pods",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopeNotTerminating, Operator: v1.ScopeSelectorOpExists},
+			},
+			details: Details{Kind: "ConfigMap"},
+			matches: false,
+		},
+		{
+			name: "best effort",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopeBestEffort, Operator: v1.ScopeSelectorOpExists},
+			},
+			details: Details{Pod: bestEffort},
+			matches: true,
+		},
+		{
+			name: "not best effort",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopeNotBestEffort, Operator: v1.ScopeSelectorOpExists},
+			},
+			details: Details{Pod: bestEffort},
+			matches: false,
+		},
+		{
+			name: "terminating",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopeTerminating, Operator: v1.ScopeSelectorOpExists},
+			},
+			details: Details{Pod: bestEffort},
+			matches: true,
+		},
+		{
+			name: "not terminating",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopeNotTerminating, Operator: v1.ScopeSelectorOpExists},
+			},
+			details: Details{Pod: guaranteed},
+			matches: true,
+		},
+		{
+			name: "priority class in",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpIn, Values: []string{"high", "medium"}},
+			},
+			details: Details{Pod: guaranteed},
+			matches: true,
+		},
+		{
+			name: "priority class in without priority class",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpIn, Values: []string{"high"}},
+			},
+			details: Details{Pod: bestEffort},
+			matches: false,
+		},
+		{
+			name: "priority class not in",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpNotIn, Values: []string{"high"}},
+			},
+			details: Details{Pod: guaranteed},
+			matches: false,
+		},
+		{
+			name: "priority class not in without priority class",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpNotIn, Values: []string{"high"}},
+			},
+			details: Details{Pod: bestEffort},
+			matches: true,
+		},
+		{
+			name: "all scopes must match",
+			selectors: []v1.ScopedResourceSelectorRequirement{
+				{ScopeName: v1.ResourceQuotaScopeNotBestEffort, Operator: v1.ScopeSelectorOpExists},
+				{ScopeName: v1.ResourceQuotaScopeTerminating, Operator: v1.ScopeSelectorOpExists},
+			},
+			details: Details{Pod: guaranteed},
+			matches: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			r.Equal(test.matches, matchesScopes(test.selectors, &test.details))
+		})
+	}
+}
+
+func TestPodDetailsCrossNamespacePodAffinity(t *testing.T) {
+	r := require.New(t)
+
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{container("app", "1", "1Gi")},
+		Affinity: &v1.Affinity{
+			PodAntiAffinity: &v1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+					{TopologyKey: "kubernetes.io/hostname", Namespaces: []string{"other"}},
+				},
+			},
+		},
+	}
+
+	r.True(podDetails(&podSpec).CrossNamespacePodAffinity)
+
+	podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].Namespaces = nil
+	r.False(podDetails(&podSpec).CrossNamespacePodAffinity)
+}
+
+func TestPodDetailsTerminating(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(priorityJob))
+	r.NoError(err)
+	r.True(usage.Details.Pod.Terminating)
+
+	// the activeDeadlineSeconds of a job is not copied to its pods
+	usage, err = ResourceQuotaFromYaml([]byte(deadlineJob))
+	r.NoError(err)
+	r.False(usage.Details.Pod.Terminating)
+	r.True(matchesScopes([]v1.ScopedResourceSelectorRequirement{
+		{ScopeName: v1.ResourceQuotaScopeNotTerminating, Operator: v1.ScopeSelectorOpExists},
+	}, &usage.Details))
+}
+
+func TestScopeTotals(t *testing.T) {
+	r := require.New(t)
+
+	calculator := NewCalculator()
+	calculator.ObjectCounts = true
+
+	var usage []*ResourceUsage
+
+	for _, manifest := range []string{normalDeployment, normalPod, priorityJob, configMap} {
+		u, err := calculator.ResourceQuotaFromYaml([]byte(manifest))
+		r.NoError(err)
+
+		usage = append(usage, u)
+	}
+
+	pods := v1.ResourceList{
+		v1.ResourcePods:               resource.MustParse("1"),
+		countPrefix + v1.ResourcePods: resource.MustParse("1"),
+	}
+	longRunning := v1.ResourceList{
+		v1.ResourceRequestsCPU:        resource.MustParse("3500m"),
+		v1.ResourceLimitsCPU:          resource.MustParse("7500m"),
+		v1.ResourceRequestsMemory:     resource.MustParse("28Gi"),
+		v1.ResourceLimitsMemory:       resource.MustParse("56Gi"),
+		v1.ResourcePods:               resource.MustParse("14"),
+		countPrefix + v1.ResourcePods: resource.MustParse("14"),
+	}
+
+	expected := map[string]v1.ResourceList{
+		"Terminating":        pods,
+		"NotTerminating":     longRunning,
+		"BestEffort":         pods,
+		"NotBestEffort":      longRunning,
+		"PriorityClass=high": pods,
+	}
+
+	totals := ScopeTotals(usage)
+	r.Len(totals, len(expected))
+
+	for _, total := range totals {
+		resources, ok := expected[total.String()]
+		r.True(ok, "unexpected scope %s", total)
+		r.Len(total.Resources, len(resources), "scope %s", total)
+
+		for name, quantity := range resources {
+			r.Truef(quantity.Equal(total.Resources[name]), "scope %s: %s expected %s, got %s",
+				total, name, quantity.String(), total.Resources.Name(name, resource.DecimalSI))
+		}
+	}
+}
@@ -1,13 +1,22 @@
 package calc
 
 import (
-	"math"
+	"fmt"
+	"strconv"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// calculates the cpu/memory resources a single statefulset needs. Replicas are taken into account.
-func statefulSet(s appsv1.StatefulSet) *ResourceUsage {
+// calculates the resources a single statefulset needs. Replicas are taken into account, every replica
+// gets its own pvc for each of the volumeClaimTemplates.
+//
+// Unlike deployments, statefulsets never run more pods than replicas. During a rolling update, a pod
+// is deleted and its replacement with the same identity is created only after the old pod is gone.
+// maxUnavailable (and the Parallel pod management policy) only affect how many pods are replaced at
+// once, a partition or the OnDelete strategy only reduce the number of replaced pods.
+func (c *Calculator) statefulSet(s appsv1.StatefulSet) (*ResourceUsage, error) {
 	var (
 		replicas int32
 	)
@@ -19,25 +28,79 @@ func statefulSet(s appsv1.StatefulSet) *ResourceUsage {
 		replicas = 1
 	}
 
-	cpu, memory := podResources(&s.Spec.Template.Spec)
+	scaled, autoscalerMax := c.scaledReplicas(s.Kind, s.Name, replicas)
 
-	mem := float64(memory.Value()) * float64(replicas)
-	memory.Set(int64(math.Round(mem)))
+	strategy := s.Spec.UpdateStrategy
 
-	cpu.Set(int64(math.Round(float64(cpu.Value()) * float64(replicas))))
+	// https://github.com/kubernetes/api/blob/v0.29.15/apps/v1/types.go#L112
+	if strategy.Type == "" {
+		strategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+	}
+
+	// like a deployment with an unknown strategy, an invalid statefulset stops the calculation, as it
+	// is rejected by the api server
+	if strategy.Type == appsv1.RollingUpdateStatefulSetStrategyType && strategy.RollingUpdate != nil {
+		if err := validateStatefulSetRollingUpdate(strategy.RollingUpdate); err != nil {
+			return nil, fmt.Errorf("statefulset: %s: %w", s.Name, err)
+		}
+	}
+
+	resources := c.podResources(&s.Spec.Template.Spec)
+
+	for i := range s.Spec.VolumeClaimTemplates {
+		addResourceList(resources, persistentVolumeClaimResources(&s.Spec.VolumeClaimTemplates[i]))
+	}
+
+	mulResourceList(resources, int64(scaled))
 
 	resourceUsage := ResourceUsage{
-		CPU:    cpu,
-		Memory: memory,
+		Resources: resources,
 		Details: Details{
-			Version:     s.APIVersion,
-			Kind:        s.Kind,
-			Name:        s.Name,
-			Replicas:    replicas,
-			Strategy:    string(s.Spec.UpdateStrategy.Type),
-			MaxReplicas: replicas,
+			Version:               s.APIVersion,
+			Kind:                  s.Kind,
+			Name:                  s.Name,
+			Replicas:              replicas,
+			Strategy:              string(strategy.Type),
+			MaxReplicas:           scaled,
+			AutoscalerMaxReplicas: autoscalerMax,
 		},
 	}
 
-	return &resourceUsage
+	return &resourceUsage, nil
+}
+
+// validateStatefulSetRollingUpdate validates the partition and maxUnavailable of a rolling update the
+// same way the api server does. Neither of them changes the calculated resources, but a statefulset
+// with an invalid rolling update is rejected by the api server and never runs any pods. The api server
+// validates the value of maxUnavailable as it is, independent of the number of replicas.
+func validateStatefulSetRollingUpdate(rollingUpdate *appsv1.RollingUpdateStatefulSetStrategy) error {
+	if rollingUpdate.Partition != nil && *rollingUpdate.Partition < 0 {
+		return fmt.Errorf("partition %d must be greater than or equal to 0", *rollingUpdate.Partition)
+	}
+
+	if rollingUpdate.MaxUnavailable == nil {
+		return nil
+	}
+
+	maxUnavailable := rollingUpdate.MaxUnavailable
+	value := int(maxUnavailable.IntVal)
+
+	if maxUnavailable.Type == intstr.String {
+		percent, err := strconv.Atoi(strings.TrimSuffix(maxUnavailable.StrVal, "%"))
+		if err != nil || !strings.HasSuffix(maxUnavailable.StrVal, "%") {
+			return fmt.Errorf("maxUnavailable %q must be an integer or percentage", maxUnavailable.StrVal)
+		}
+
+		if percent > 100 {
+			return fmt.Errorf("maxUnavailable %s must not be greater than 100%%", maxUnavailable.StrVal)
+		}
+
+		value = percent
+	}
+
+	if value < 1 {
+		return fmt.Errorf("maxUnavailable %s must be greater than 0", maxUnavailable.String())
+	}
+
+	return nil
 }
@@ -5,36 +5,77 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestStatefulSet(t *testing.T) {
 	var tests = []struct {
-		name        string
-		statefulset string
-		cpu         resource.Quantity
-		memory      resource.Quantity
-		replicas    int32
-		maxReplicas int32
-		strategy    appsv1.StatefulSetUpdateStrategyType
+		name           string
+		statefulset    string
+		cpu            resource.Quantity
+		memory         resource.Quantity
+		cpuRequests    resource.Quantity
+		memoryRequests resource.Quantity
+		replicas       int32
+		maxReplicas    int32
+		strategy       appsv1.StatefulSetUpdateStrategyType
 	}{
 		{
-			name:        "ok",
-			statefulset: normalStatefulSet,
-			cpu:         resource.MustParse("2"),
-			memory:      resource.MustParse("8Gi"),
-			replicas:    2,
-			maxReplicas: 2,
-			strategy:    appsv1.RollingUpdateStatefulSetStrategyType,
+			name:           "ok",
+			statefulset:    normalStatefulSet,
+			cpu:            resource.MustParse("2"),
+			memory:         resource.MustParse("8Gi"),
+			cpuRequests:    resource.MustParse("500m"),
+			memoryRequests: resource.MustParse("4Gi"),
+			replicas:       2,
+			maxReplicas:    2,
+			strategy:       appsv1.RollingUpdateStatefulSetStrategyType,
 		},
 		{
-			name:        "no replicas",
-			statefulset: noReplicasStatefulSet,
-			cpu:         resource.MustParse("1"),
-			memory:      resource.MustParse("4Gi"),
-			replicas:    1,
-			maxReplicas: 1,
-			strategy:    appsv1.RollingUpdateStatefulSetStrategyType,
+			name:           "fractional cpu",
+			statefulset:    fractionalCPUStatefulSet,
+			cpu:            resource.MustParse("1500m"),
+			memory:         resource.MustParse("4.5Gi"),
+			cpuRequests:    resource.MustParse("999m"),
+			memoryRequests: resource.MustParse("301.5Mi"),
+			replicas:       3,
+			maxReplicas:    3,
+			strategy:       appsv1.RollingUpdateStatefulSetStrategyType,
+		},
+		{
+			name:           "partition, max unavailable and parallel pod management",
+			statefulset:    partitionStatefulSet,
+			cpu:            resource.MustParse("5"),
+			memory:         resource.MustParse("20Gi"),
+			cpuRequests:    resource.MustParse("1250m"),
+			memoryRequests: resource.MustParse("10Gi"),
+			replicas:       5,
+			maxReplicas:    5,
+			strategy:       appsv1.RollingUpdateStatefulSetStrategyType,
+		},
+		{
+			name:           "on delete",
+			statefulset:    onDeleteStatefulSet,
+			cpu:            resource.MustParse("5"),
+			memory:         resource.MustParse("20Gi"),
+			cpuRequests:    resource.MustParse("1250m"),
+			memoryRequests: resource.MustParse("10Gi"),
+			replicas:       5,
+			maxReplicas:    5,
+			strategy:       appsv1.OnDeleteStatefulSetStrategyType,
+		},
+		{
+			name:           "no replicas",
+			statefulset:    noReplicasStatefulSet,
+			cpu:            resource.MustParse("1"),
+			memory:         resource.MustParse("4Gi"),
+			cpuRequests:    resource.MustParse("250m"),
+			memoryRequests: resource.MustParse("2Gi"),
+			replicas:       1,
+			maxReplicas:    1,
+			strategy:       appsv1.RollingUpdateStatefulSetStrategyType,
 		},
 	}
 
@@ -46,11 +87,92 @@ func TestStatefulSet(t *testing.T) {
 			r.NoError(err)
 			r.NotEmpty(usage)
 
-			r.Equalf(test.cpu.Value(), usage.CPU.Value(), "cpu value")
-			r.Equalf(test.memory.Value(), usage.Memory.Value(), "memory value")
+			r.Equalf(test.cpu.MilliValue(), quantity(usage, v1.ResourceLimitsCPU).MilliValue(), "cpu value")
+			r.Equalf(test.memory.Value(), quantity(usage, v1.ResourceLimitsMemory).Value(), "memory value")
+			r.Equalf(test.cpuRequests.MilliValue(), quantity(usage, v1.ResourceRequestsCPU).MilliValue(), "cpu requests value")
+			r.Equalf(test.memoryRequests.Value(), quantity(usage, v1.ResourceRequestsMemory).Value(), "memory requests value")
 			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
 			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
 			r.Equalf(string(test.strategy), usage.Details.Strategy, "strategy")
 		})
 	}
 }
+
+func TestStatefulSetInvalidMaxUnavailable(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ResourceQuotaFromYaml([]byte(invalidMaxUnavailableStatefulSet))
+	r.Error(err)
+}
+
+func TestValidateStatefulSetRollingUpdate(t *testing.T) {
+	var tests = []struct {
+		name           string
+		maxUnavailable intstr.IntOrString
+		valid          bool
+	}{
+		{
+			name:           "absolute value",
+			maxUnavailable: intstr.FromInt32(2),
+			valid:          true,
+		},
+		{
+			name:           "percentage",
+			maxUnavailable: intstr.FromString("100%"),
+			valid:          true,
+		},
+		{
+			name:           "zero",
+			maxUnavailable: intstr.FromInt32(0),
+		},
+		{
+			name:           "zero percent",
+			maxUnavailable: intstr.FromString("0%"),
+		},
+		{
+			name:           "negative value",
+			maxUnavailable: intstr.FromInt32(-1),
+		},
+		{
+			name:           "more than 100 percent",
+			maxUnavailable: intstr.FromString("150%"),
+		},
+		{
+			name:           "no percentage",
+			maxUnavailable: intstr.FromString("many"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			err := validateStatefulSetRollingUpdate(&appsv1.RollingUpdateStatefulSetStrategy{
+				MaxUnavailable: &test.maxUnavailable,
+			})
+
+			if test.valid {
+				r.NoError(err)
+			} else {
+				r.Error(err)
+			}
+		})
+	}
+}
+
+func TestStatefulSetVolumeClaimTemplates(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(volumeClaimTemplateStatefulSet))
+	r.NoError(err)
+	r.NotEmpty(usage)
+
+	storage := resource.MustParse("33Gi")
+	goldStorage := resource.MustParse("30Gi")
+
+	r.Equal(int64(1500), quantity(usage, v1.ResourceLimitsCPU).MilliValue())
+	r.Equal(storage.Value(), quantity(usage, v1.ResourceRequestsStorage).Value())
+	r.Equal(int64(6), quantity(usage, v1.ResourcePersistentVolumeClaims).Value())
+	r.Equal(goldStorage.Value(), quantity(usage, "gold.storageclass.storage.k8s.io/requests.storage").Value())
+	r.Equal(int64(3), quantity(usage, "gold.storageclass.storage.k8s.io/persistentvolumeclaims").Value())
+}